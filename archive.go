@@ -0,0 +1,461 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+
+	"gorgonia.org/tensor"
+)
+
+// ##############################################################
+// Archive of the non-dominated points found across iterations or runs.
+//
+// References:
+//  - Kung, H.T., Luccio, F., Preparata, F.P.: On Finding the Maxima of a
+//    Set of Vectors. Journal of the ACM 22(4) (1975), 469-476
+//  - Laumanns, M., Thiele, L., Deb, K., Zitzler, E.: Combining Convergence
+//    and Diversity in Evolutionary Multiobjective Optimization.
+//    Evolutionary Computation 10(3) (2002), 263-282
+// ##############################################################
+
+// ArchivePruning How a bounded archive chooses the member to remove when it is full
+type ArchivePruning uint8
+
+// The available pruning strategies
+const (
+	CrowdingPruning    ArchivePruning = iota // remove the most crowded member (smallest crowding distance)
+	HypervolumePruning                       // remove the member contributing the least hypervolume (exact, slow beyond 3 objectives)
+)
+
+// ParetoArchive : A set of mutually non-dominated points. Inserting a point removes the
+// members it dominates, and rejects it if a member dominates it (or has the same objective
+// values). The constraints are taken into account: a lower violation dominates.
+// In epsilon mode, the objective space is split in boxes of size epsilon, and the archive keeps
+// at most one point per non-dominated box: its size stays bounded without pruning.
+type ParetoArchive struct {
+	members  []Point
+	capacity int            // maximum number of members, 0 for no limit
+	pruning  ArchivePruning // how to choose the member to remove when there are too many
+	epsilon  []float64      // box sizes for the epsilon-dominance, nil for the Pareto dominance
+}
+
+// newParetoArchive Creates an empty archive, of unlimited size if capacity is 0
+func newParetoArchive(capacity int, pruning ArchivePruning) *ParetoArchive {
+	return &ParetoArchive{capacity: capacity, pruning: pruning}
+}
+
+// newEpsilonArchive Creates an empty archive using the epsilon-dominance, with one box size per objective
+func newEpsilonArchive(epsilon []float64) *ParetoArchive {
+	return &ParetoArchive{epsilon: epsilon}
+}
+
+// weaklyDominatesPoint Tells if a is at least as good as b: lower constraint violation, or the same
+// violation and objective values lower or equal.
+func weaklyDominatesPoint(a, b *Point) bool {
+	if a.violation != b.violation {
+		return a.violation < b.violation
+	}
+	var fa, fb = imagesOf(a), imagesOf(b)
+	for k := range fa {
+		if fa[k] > fb[k] {
+			return false
+		}
+	}
+	return true
+}
+
+// box The index of the epsilon-box of a point, in the objective space
+func (a *ParetoArchive) box(pt *Point) []float64 {
+	var f = imagesOf(pt)
+	var index = make([]float64, len(f))
+	for k := range f {
+		index[k] = math.Floor(f[k] / a.epsilon[k])
+	}
+	return index
+}
+
+// insert Adds a point to the archive, if it is not dominated. Returns true if it was added.
+func (a *ParetoArchive) insert(pt Point) bool {
+	if a.epsilon != nil {
+		return a.insertEpsilon(pt)
+	}
+	for i := range a.members {
+		if weaklyDominatesPoint(&a.members[i], &pt) {
+			return false
+		}
+	}
+	var kept = a.members[:0]
+	for i := range a.members {
+		if !weaklyDominatesPoint(&pt, &a.members[i]) {
+			kept = append(kept, a.members[i])
+		}
+	}
+	a.members = append(kept, pt)
+	a.prune()
+	return true
+}
+
+// insertEpsilon Insertion with the epsilon-dominance: the point is rejected if the box of a member
+// dominates its box, or if a member of the same box is closer to the corner of the box.
+func (a *ParetoArchive) insertEpsilon(pt Point) bool {
+	var b = a.box(&pt)
+	var kept = make([]Point, 0, len(a.members)+1)
+	for i := range a.members {
+		var member = &a.members[i]
+		if member.violation != pt.violation {
+			if member.violation < pt.violation {
+				return false
+			}
+			continue // the new point has a lower violation, the member is removed
+		}
+		var mb = a.box(member)
+		switch {
+		case floatsEqual(mb, b):
+			if dominates(imagesOf(&pt), imagesOf(member)) || distanceToCorner(&pt, b, a.epsilon) < distanceToCorner(member, b, a.epsilon) {
+				continue // the new point replaces the member of its box
+			}
+			return false
+		case dominates(mb, b):
+			return false
+		case !dominates(b, mb):
+			kept = append(kept, *member)
+		}
+	}
+	a.members = append(kept, pt)
+	return true
+}
+
+// floatsEqual Tells if two vectors are equal
+func floatsEqual(a, b []float64) bool {
+	for k := range a {
+		if a[k] != b[k] {
+			return false
+		}
+	}
+	return true
+}
+
+// distanceToCorner The distance of a point to the lower corner of its epsilon-box
+func distanceToCorner(pt *Point, box, epsilon []float64) float64 {
+	var sum = 0.0
+	for k, fk := range imagesOf(pt) {
+		var d = fk - box[k]*epsilon[k]
+		sum += d * d
+	}
+	return math.Sqrt(sum)
+}
+
+// insertAll Adds many points at once, filtering the non-dominated ones with Kung's method
+// (see kungFilter). Returns the number of points which were added.
+func (a *ParetoArchive) insertAll(points []Point) int {
+	if a.epsilon != nil {
+		var added = 0
+		for _, pt := range points {
+			if a.insert(pt) {
+				added++
+			}
+		}
+		return added
+	}
+	var previous = make(map[*tensor.Dense]bool, len(a.members)) // the copies of a point share its images
+	for i := range a.members {
+		previous[a.members[i].images] = true
+	}
+	a.members = kungFilter(append(append([]Point{}, a.members...), points...))
+	var added = 0
+	for i := range a.members {
+		if !previous[a.members[i].images] {
+			added++
+		}
+	}
+	a.prune()
+	return added
+}
+
+// kungFilter Returns the points which are not weakly dominated by another one (the first of
+// duplicates is kept), sorted lexicographically, with Kung's method. Only the points of lowest
+// violation can be kept. Once they are sorted, none can be dominated by a point after it: a
+// sweep finds the front in O(n log n) for 2 and 3 objectives, and beyond the front of the first
+// half is merged with the front of the second half by a recursion on the objectives.
+func kungFilter(points []Point) []Point {
+	var front = []Point{}
+	if len(points) == 0 {
+		return front
+	}
+	var lowest = points[0].violation
+	for a := range points {
+		lowest = math.Min(lowest, points[a].violation)
+	}
+	var candidates = []int{}
+	for a := range points {
+		if points[a].violation == lowest {
+			candidates = append(candidates, a)
+		}
+	}
+	var all = imagesOfPoints(points)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		var fi, fj = all[candidates[i]], all[candidates[j]]
+		for k := range fi {
+			if fi[k] != fj[k] {
+				return fi[k] < fj[k]
+			}
+		}
+		return false
+	})
+	var images = make([][]float64, len(candidates))
+	for a := range candidates {
+		images[a] = all[candidates[a]]
+	}
+
+	var kept []int
+	switch len(images[0]) {
+	case 1:
+		kept = []int{0}
+	case 2:
+		kept = kungSweep2D(images)
+	case 3:
+		kept = kungSweep3D(images)
+	default:
+		var indices = make([]int, len(images))
+		for a := range indices {
+			indices[a] = a
+		}
+		kept = kungFront(images, indices, make([]bool, len(images)))
+	}
+	for _, a := range kept {
+		front = append(front, points[candidates[a]])
+	}
+	return front
+}
+
+// kungSweep2D The front of lexicographically sorted vectors of 2 objectives: the indices of those
+// whose second objective is lower than all the previous ones.
+func kungSweep2D(images [][]float64) []int {
+	var kept = []int{}
+	var best = math.Inf(1)
+	for a, f := range images {
+		if f[1] < best {
+			kept = append(kept, a)
+			best = f[1]
+		}
+	}
+	return kept
+}
+
+// stairStep : A node of the treap used by kungSweep3D, which holds the last two objectives of the
+// front found so far: sorted by increasing f2, so by decreasing f3 since none dominates another.
+type stairStep struct {
+	f2, f3      float64
+	priority    uint64
+	left, right *stairStep
+}
+
+// splitStairs Splits a treap between the first steps, for which isLeft is true, and the others
+func splitStairs(t *stairStep, isLeft func(s *stairStep) bool) (*stairStep, *stairStep) {
+	if t == nil {
+		return nil, nil
+	}
+	if isLeft(t) {
+		var l, r = splitStairs(t.right, isLeft)
+		t.right = l
+		return t, r
+	}
+	var l, r = splitStairs(t.left, isLeft)
+	t.left = r
+	return l, t
+}
+
+// mergeStairs Concatenates two treaps, all the steps of a being before those of b
+func mergeStairs(a, b *stairStep) *stairStep {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	if a.priority > b.priority {
+		a.right = mergeStairs(a.right, b)
+		return a
+	}
+	b.left = mergeStairs(a, b.left)
+	return b
+}
+
+// kungSweep3D The front of lexicographically sorted vectors of 3 objectives: a vector is kept if
+// no previous one of the front is lower or equal on the last two objectives. The front found so
+// far is a staircase in a treap: the step with the largest f2 below that of the vector has the
+// lowest f3 to compare with, and the steps the new vector covers are removed.
+func kungSweep3D(images [][]float64) []int {
+	var kept = []int{}
+	var rng = rand.New(rand.NewSource(int64(len(images))))
+	var root *stairStep
+	for a, f := range images {
+		var covered = false
+		for s := root; s != nil; {
+			if s.f2 <= f[1] {
+				covered = s.f3 <= f[2]
+				s = s.right
+			} else {
+				s = s.left
+			}
+		}
+		if covered {
+			continue
+		}
+		kept = append(kept, a)
+		var below, above = splitStairs(root, func(s *stairStep) bool { return s.f2 < f[1] })
+		_, above = splitStairs(above, func(s *stairStep) bool { return s.f3 >= f[2] })
+		var step = &stairStep{f2: f[1], f3: f[2], priority: rng.Uint64()}
+		root = mergeStairs(mergeStairs(below, step), above)
+	}
+	return kept
+}
+
+// kungFront The recursive step of kungFilter beyond 3 objectives, on lexicographically sorted
+// indices: the points of the front of the second half which are weakly dominated by the front of
+// the first half are marked as dominated, and the others are appended to it.
+func kungFront(images [][]float64, indices []int, dominated []bool) []int {
+	if len(indices) <= 1 {
+		return indices
+	}
+	var top = kungFront(images, indices[:len(indices)/2], dominated)
+	var bottom = kungFront(images, indices[len(indices)/2:], dominated)
+	kungMark(images, top, bottom, 1, dominated)
+	var front = make([]int, 0, len(top)+len(bottom))
+	front = append(front, top...)
+	for _, b := range bottom {
+		if !dominated[b] {
+			front = append(front, b)
+		}
+	}
+	return front
+}
+
+// kungMark Marks the points of bottom which are weakly dominated by a point of top, knowing that
+// the points of top are lower or equal to those of bottom on the objectives before k. Both sets
+// are split around the median of objective k: the lower part of top is then lower or equal to
+// the upper part of bottom on objective k, and the upper part of top cannot dominate the lower
+// part of bottom. The last two objectives are handled by a sweep.
+func kungMark(images [][]float64, top, bottom []int, k int, dominated []bool) {
+	if len(top) == 0 || len(bottom) == 0 {
+		return
+	}
+	var nDims = len(images[top[0]])
+	switch {
+	case k == nDims-1:
+		var best = math.Inf(1)
+		for _, t := range top {
+			best = math.Min(best, images[t][k])
+		}
+		for _, b := range bottom {
+			if images[b][k] >= best {
+				dominated[b] = true
+			}
+		}
+		return
+	case k == nDims-2:
+		var byK = func(indices []int) []int {
+			var s = append([]int{}, indices...)
+			sort.Slice(s, func(i, j int) bool { return images[s[i]][k] < images[s[j]][k] })
+			return s
+		}
+		var sortedTop = byK(top)
+		var best = math.Inf(1)
+		var t = 0
+		for _, b := range byK(bottom) {
+			for ; t < len(sortedTop) && images[sortedTop[t]][k] <= images[b][k]; t++ {
+				best = math.Min(best, images[sortedTop[t]][k+1])
+			}
+			if best <= images[b][k+1] {
+				dominated[b] = true
+			}
+		}
+		return
+	case len(top)*len(bottom) <= 64:
+		for _, b := range bottom {
+			for _, t := range top {
+				if weaklyDominatesFrom(images[t], images[b], k) {
+					dominated[b] = true
+					break
+				}
+			}
+		}
+		return
+	}
+
+	var values = make([]float64, 0, len(top)+len(bottom))
+	for _, a := range append(append([]int{}, top...), bottom...) {
+		values = append(values, images[a][k])
+	}
+	sort.Float64s(values)
+	var highest = values[len(values)-1]
+	if values[0] == highest {
+		kungMark(images, top, bottom, k+1, dominated)
+		return
+	}
+	var median = values[(len(values)-1)/2]
+	if median == highest {
+		median = values[sort.SearchFloat64s(values, highest)-1]
+	}
+	var split = func(indices []int) (lower, upper []int) {
+		for _, a := range indices {
+			if images[a][k] <= median {
+				lower = append(lower, a)
+			} else {
+				upper = append(upper, a)
+			}
+		}
+		return lower, upper
+	}
+	var topLower, topUpper = split(top)
+	var bottomLower, bottomUpper = split(bottom)
+	kungMark(images, topLower, bottomLower, k, dominated)
+	kungMark(images, topUpper, bottomUpper, k, dominated)
+	kungMark(images, topLower, bottomUpper, k+1, dominated)
+}
+
+// weaklyDominatesFrom Tells if a is lower or equal to b on the objectives from k
+func weaklyDominatesFrom(a, b []float64, k int) bool {
+	for ; k < len(a); k++ {
+		if a[k] > b[k] {
+			return false
+		}
+	}
+	return true
+}
+
+// prune Removes members until the archive fits its capacity
+func (a *ParetoArchive) prune() {
+	for a.capacity > 0 && len(a.members) > a.capacity {
+		var all = make([]int, len(a.members))
+		for i := range all {
+			all[i] = i
+		}
+		var score []float64
+		if a.pruning == HypervolumePruning {
+			// a reference point far beyond the worst values favours the extreme members
+			var images = imagesOfPoints(a.members)
+			score = hypervolumeContributions(images, referencePoint(images, 1))
+		} else {
+			score = crowdingDistance(a.members, all)
+		}
+		var worst = 0
+		for i := range score {
+			if score[i] < score[worst] {
+				worst = i
+			}
+		}
+		a.members = append(a.members[:worst], a.members[worst+1:]...)
+	}
+}
+
+// getMembers Returns a copy of the non-dominated points of the archive
+func (a *ParetoArchive) getMembers() []Point {
+	return append([]Point{}, a.members...)
+}
+
+// size The number of points in the archive
+func (a *ParetoArchive) size() int {
+	return len(a.members)
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+
+	"gonum.org/v1/gonum/floats"
+	"gorgonia.org/tensor"
+)
+
+// pointsOfImages Points with the given objective values and violations, without inputs, of a
+// problem which only knows its number of objectives
+func pointsOfImages(images [][]float64, violations []float64) []Point {
+	var points = make([]Point, len(images))
+	for a := range images {
+		var p = &Problem{nDims: len(images[a])}
+		points[a] = Point{images: tensor.New(tensor.WithShape(len(images[a]), 1), tensor.WithBacking(images[a])), Problem: p, violation: violations[a]}
+	}
+	return points
+}
+
+// bruteForceFront The points kept by kungFilter, by comparing all the pairs
+func bruteForceFront(points []Point) map[*tensor.Dense]bool {
+	var front = map[*tensor.Dense]bool{}
+	for i := range points {
+		var kept = true
+		for j := range points {
+			if j != i && weaklyDominatesPoint(&points[j], &points[i]) && (j < i || !weaklyDominatesPoint(&points[i], &points[j])) {
+				kept = false
+				break
+			}
+		}
+		if kept {
+			front[points[i].images] = true
+		}
+	}
+	return front
+}
+
+func TestKungFilter(t *testing.T) {
+	var rng = rand.New(rand.NewSource(1))
+	for nDims := 1; nDims <= 6; nDims++ {
+		for _, size := range []int{0, 1, 2, 10, 100, 1000} {
+			// few distinct values, for many ties and duplicates
+			var images = make([][]float64, size)
+			var violations = make([]float64, size)
+			for a := range images {
+				images[a] = make([]float64, nDims)
+				for k := range images[a] {
+					images[a][k] = float64(rng.Intn(8))
+				}
+				if rng.Intn(10) == 0 {
+					violations[a] = 1
+				}
+			}
+			var points = pointsOfImages(images, violations)
+			var want = bruteForceFront(points)
+			var got = kungFilter(points)
+			if len(got) != len(want) {
+				t.Errorf("%d objectives, %d points: %d kept, want %d", nDims, size, len(got), len(want))
+				continue
+			}
+			for a := range got {
+				if !want[got[a].images] {
+					t.Errorf("%d objectives, %d points: %v is dominated", nDims, size, imagesOf(&got[a]))
+				}
+				if a > 0 && !lexicographicallyBefore(imagesOf(&got[a-1]), imagesOf(&got[a])) {
+					t.Errorf("%d objectives, %d points: %v before %v", nDims, size, imagesOf(&got[a-1]), imagesOf(&got[a]))
+				}
+			}
+		}
+	}
+}
+
+func TestKungFilterNonDominated(t *testing.T) {
+	// points on the plane where the objectives sum to 1: none dominates another
+	var rng = rand.New(rand.NewSource(2))
+	for nDims := 2; nDims <= 5; nDims++ {
+		var images = make([][]float64, 2000)
+		for a := range images {
+			images[a] = make([]float64, nDims)
+			var sum = 0.0
+			for k := range images[a] {
+				images[a][k] = rng.Float64()
+				sum += images[a][k]
+			}
+			for k := range images[a] {
+				images[a][k] /= sum
+			}
+		}
+		var archive = newParetoArchive(0, CrowdingPruning)
+		if added := archive.insertAll(pointsOfImages(images, make([]float64, len(images)))); added != len(images) {
+			t.Errorf("%d objectives: %d points added, want %d", nDims, added, len(images))
+		}
+	}
+}
+
+// sameImages Tells if the archive holds exactly the points of these objective values, in any order
+func sameImages(members []Point, want [][]float64) bool {
+	if len(members) != len(want) {
+		return false
+	}
+	for _, w := range want {
+		var found = false
+		for a := range members {
+			found = found || floats.Equal(imagesOf(&members[a]), w)
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func TestParetoArchiveInsert(t *testing.T) {
+	var archive = newParetoArchive(0, CrowdingPruning)
+	var steps = []struct {
+		image     []float64
+		violation float64
+		added     bool
+	}{
+		{[]float64{1, 3}, 0, true},
+		{[]float64{3, 1}, 0, true},
+		{[]float64{2, 2}, 0, true},
+		{[]float64{2, 2}, 0, false}, // duplicate
+		{[]float64{2, 3}, 0, false}, // dominated by (1, 3)
+		{[]float64{0, 4}, 0, true},
+		{[]float64{1, 1}, 0, true},  // removes (1, 3), (2, 2) and (3, 1)
+		{[]float64{0, 0}, 1, false}, // infeasible
+	}
+	for _, s := range steps {
+		var pt = pointsOfImages([][]float64{s.image}, []float64{s.violation})[0]
+		if added := archive.insert(pt); added != s.added {
+			t.Errorf("insert %v (violation %g): %v, want %v", s.image, s.violation, added, s.added)
+		}
+	}
+	if members := archive.getMembers(); !sameImages(members, [][]float64{{0, 4}, {1, 1}}) {
+		t.Errorf("members %v, want (0, 4) and (1, 1)", imagesOfPoints(members))
+	}
+}
+
+func TestEpsilonArchiveInsert(t *testing.T) {
+	var archive = newEpsilonArchive([]float64{1, 1})
+	var steps = []struct {
+		image []float64
+		added bool
+	}{
+		{[]float64{0.5, 2.5}, true},
+		{[]float64{0.2, 2.2}, true},  // same box, closer to its corner (0, 2): replaces (0.5, 2.5)
+		{[]float64{0.8, 2.1}, false}, // same box, farther from the corner
+		{[]float64{1.5, 1.5}, true},  // box (1, 1)
+		{[]float64{1.9, 2.9}, false}, // box (1, 2), dominated by the box (1, 1)
+		{[]float64{0.1, 1.1}, true},  // box (0, 1), dominates the two others
+	}
+	for _, s := range steps {
+		var pt = pointsOfImages([][]float64{s.image}, []float64{0})[0]
+		if added := archive.insert(pt); added != s.added {
+			t.Errorf("insert %v: %v, want %v", s.image, added, s.added)
+		}
+	}
+	if members := archive.getMembers(); !sameImages(members, [][]float64{{0.1, 1.1}}) {
+		t.Errorf("members %v, want (0.1, 1.1)", imagesOfPoints(members))
+	}
+}
+
+func TestArchivePruningKeepsExtremes(t *testing.T) {
+	// 20 points of the front f2 = 1 - f1, inserted in a random order
+	var rng = rand.New(rand.NewSource(3))
+	var images = make([][]float64, 20)
+	for a, i := range rng.Perm(len(images)) {
+		images[a] = []float64{float64(i) / 19, 1 - float64(i)/19}
+	}
+	for _, pruning := range []ArchivePruning{CrowdingPruning, HypervolumePruning} {
+		var archive = newParetoArchive(5, pruning)
+		for _, pt := range pointsOfImages(images, make([]float64, len(images))) {
+			archive.insert(pt)
+		}
+		if archive.size() != 5 {
+			t.Errorf("pruning %d: %d members, want 5", pruning, archive.size())
+		}
+		var members = archive.getMembers()
+		for _, extreme := range [][]float64{{0, 1}, {1, 0}} {
+			var found = false
+			for a := range members {
+				found = found || floats.Equal(imagesOf(&members[a]), extreme)
+			}
+			if !found {
+				t.Errorf("pruning %d: the extreme point %v was removed from %v", pruning, extreme, imagesOfPoints(members))
+			}
+		}
+	}
+}
+
+// lexicographicallyBefore Tells if a is strictly lower than b in the lexicographic order
+func lexicographicallyBefore(a, b []float64) bool {
+	for k := range a {
+		if a[k] != b[k] {
+			return a[k] < b[k]
+		}
+	}
+	return false
+}
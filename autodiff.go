@@ -0,0 +1,292 @@
+package main
+
+import (
+	"math"
+
+	"gorgonia.org/tensor"
+)
+
+// ##############################################################
+// Automatic differentiation, for the objectives which cannot be
+// written as equations (loops, branches, geometry...).
+// The objective is written once against ADVar values, recorded on
+// a Tape, and differentiated exactly:
+//  - in forward mode (one pass per variable) when nVars <= nDims,
+//  - in reverse mode (one sweep per objective) otherwise,
+//  - in forward-over-reverse mode for the Hessians.
+// ##############################################################
+
+// ADFunction An objective function R^M -> R^N written with ADVar operations.
+// The Tape is used to create constants, the inputs are the M variables.
+type ADFunction func(t *Tape, x []ADVar) []ADVar
+
+// dual A dual number val + dot.eps, with eps² = 0. dot carries the directional
+// derivative along the seed direction of the forward pass.
+type dual struct {
+	val, dot float64
+}
+
+func (a dual) add(b dual) dual { return dual{a.val + b.val, a.dot + b.dot} }
+func (a dual) mul(b dual) dual { return dual{a.val * b.val, a.val*b.dot + a.dot*b.val} }
+func (a dual) scale(c float64) dual {
+	return dual{c * a.val, c * a.dot}
+}
+func (a dual) div(b dual) dual {
+	return dual{a.val / b.val, (a.dot*b.val - a.val*b.dot) / (b.val * b.val)}
+}
+
+// tapeNode An operation recorded on the tape: its value, and the local
+// partial derivatives with respect to its (at most two) parents.
+type tapeNode struct {
+	value    dual
+	parents  [2]int
+	partials [2]dual
+	nParents int
+}
+
+// Tape The record of all the operations of an evaluation of an ADFunction.
+type Tape struct {
+	nodes []tapeNode
+}
+
+// ADVar A value recorded on a Tape.
+type ADVar struct {
+	tape  *Tape
+	index int
+}
+
+func (t *Tape) push(n tapeNode) ADVar {
+	t.nodes = append(t.nodes, n)
+	return ADVar{t, len(t.nodes) - 1}
+}
+
+// constant Records a constant value.
+func (t *Tape) constant(c float64) ADVar {
+	return t.push(tapeNode{value: dual{c, 0}})
+}
+
+// value Returns the current value of the variable, for instance to branch on it.
+func (a ADVar) value() float64 {
+	return a.tape.nodes[a.index].value.val
+}
+
+func (a ADVar) dualValue() dual {
+	return a.tape.nodes[a.index].value
+}
+
+func (a ADVar) binary(b ADVar, value, da, db dual) ADVar {
+	return a.tape.push(tapeNode{value, [2]int{a.index, b.index}, [2]dual{da, db}, 2})
+}
+
+func (a ADVar) unary(value, da dual) ADVar {
+	return a.tape.push(tapeNode{value, [2]int{a.index, 0}, [2]dual{da, {}}, 1})
+}
+
+// unaryFunction Records g(a), given the values of g and of its first and second derivatives at a.
+func (a ADVar) unaryFunction(g, g1, g2 float64) ADVar {
+	var ad = a.dualValue()
+	return a.unary(dual{g, g1 * ad.dot}, dual{g1, g2 * ad.dot})
+}
+
+func (a ADVar) add(b ADVar) ADVar {
+	return a.binary(b, a.dualValue().add(b.dualValue()), dual{1, 0}, dual{1, 0})
+}
+
+func (a ADVar) sub(b ADVar) ADVar {
+	return a.binary(b, a.dualValue().add(b.dualValue().scale(-1)), dual{1, 0}, dual{-1, 0})
+}
+
+func (a ADVar) mul(b ADVar) ADVar {
+	var ad, bd = a.dualValue(), b.dualValue()
+	return a.binary(b, ad.mul(bd), bd, ad)
+}
+
+func (a ADVar) div(b ADVar) ADVar {
+	var ad, bd = a.dualValue(), b.dualValue()
+	var v = ad.div(bd)
+	return a.binary(b, v, dual{1, 0}.div(bd), v.div(bd).scale(-1))
+}
+
+func (a ADVar) neg() ADVar {
+	return a.unary(a.dualValue().scale(-1), dual{-1, 0})
+}
+
+func (a ADVar) addConst(c float64) ADVar {
+	var ad = a.dualValue()
+	return a.unary(dual{ad.val + c, ad.dot}, dual{1, 0})
+}
+
+func (a ADVar) mulConst(c float64) ADVar {
+	return a.unary(a.dualValue().scale(c), dual{c, 0})
+}
+
+// powConst Records a**c for a constant exponent c.
+func (a ADVar) powConst(c float64) ADVar {
+	var x = a.value()
+	return a.unaryFunction(math.Pow(x, c), c*math.Pow(x, c-1), c*(c-1)*math.Pow(x, c-2))
+}
+
+// pow Records a**b, defined for a > 0.
+func (a ADVar) pow(b ADVar) ADVar {
+	return b.mul(a.log()).exp()
+}
+
+func (a ADVar) sin() ADVar {
+	var x = a.value()
+	return a.unaryFunction(math.Sin(x), math.Cos(x), -math.Sin(x))
+}
+
+func (a ADVar) cos() ADVar {
+	var x = a.value()
+	return a.unaryFunction(math.Cos(x), -math.Sin(x), -math.Cos(x))
+}
+
+func (a ADVar) exp() ADVar {
+	var e = math.Exp(a.value())
+	return a.unaryFunction(e, e, e)
+}
+
+func (a ADVar) log() ADVar {
+	var x = a.value()
+	return a.unaryFunction(math.Log(x), 1/x, -1/(x*x))
+}
+
+func (a ADVar) sqrt() ADVar {
+	var s = math.Sqrt(a.value())
+	return a.unaryFunction(s, 0.5/s, -0.25/(s*s*s))
+}
+
+func (a ADVar) tanh() ADVar {
+	var th = math.Tanh(a.value())
+	return a.unaryFunction(th, 1-th*th, -2*th*(1-th*th))
+}
+
+func (a ADVar) atan() ADVar {
+	var x = a.value()
+	var d = 1 / (1 + x*x)
+	return a.unaryFunction(math.Atan(x), d, -2*x*d*d)
+}
+
+// abs Records |a|, whose derivative is taken as the sign of a (0 in 0).
+func (a ADVar) abs() ADVar {
+	var x = a.value()
+	var sign = 0.0
+	if x > 0 {
+		sign = 1
+	} else if x < 0 {
+		sign = -1
+	}
+	return a.unaryFunction(math.Abs(x), sign, 0)
+}
+
+// ##############################################################
+// Evaluation and differentiation of an ADFunction
+// ##############################################################
+
+// record Evaluates fn at x on a new tape. seed is the direction of the
+// forward pass (the dot parts of the inputs), or nil.
+func record(fn ADFunction, x []float64, seed []float64) (*Tape, []ADVar) {
+	var t = &Tape{make([]tapeNode, 0, 16*len(x))}
+	var inputs = make([]ADVar, len(x))
+	for i := range x {
+		var n = tapeNode{value: dual{x[i], 0}}
+		if seed != nil {
+			n.value.dot = seed[i]
+		}
+		inputs[i] = t.push(n)
+	}
+	return t, fn(t, inputs)
+}
+
+// reverseSweep Backpropagates the adjoints from the given output to the
+// first nInputs nodes of the tape (the variables). The val parts of the
+// returned adjoints are the gradient of the output, their dot parts are
+// the product of its Hessian with the seed of the forward pass.
+func (t *Tape) reverseSweep(output ADVar, nInputs int) []dual {
+	var size = output.index + 1
+	if size < nInputs {
+		size = nInputs
+	}
+	var adjoints = make([]dual, size)
+	adjoints[output.index] = dual{1, 0}
+	for i := output.index; i >= nInputs; i-- {
+		var n = &t.nodes[i]
+		if adjoints[i] == (dual{}) {
+			continue
+		}
+		for k := 0; k < n.nParents; k++ {
+			adjoints[n.parents[k]] = adjoints[n.parents[k]].add(adjoints[i].mul(n.partials[k]))
+		}
+	}
+	return adjoints[:nInputs]
+}
+
+// adObjectives Evaluates an ADFunction, R^M -> R^N
+func adObjectives(fn ADFunction, x []float64) *tensor.Dense {
+	var _, outputs = record(fn, x, nil)
+	var images = make([]float64, len(outputs))
+	for k, out := range outputs {
+		images[k] = out.value()
+	}
+	return tensor.New(tensor.WithShape(len(outputs), 1), tensor.WithBacking(images))
+}
+
+// adJacobian Differentiates an ADFunction, R^M -> M(N, M)
+func adJacobian(fn ADFunction, x []float64) *tensor.Dense {
+	var nVars = len(x)
+	var t, outputs = record(fn, x, nil)
+	var nDims = len(outputs)
+	var backing = make([]float64, nDims*nVars)
+
+	if nVars <= nDims {
+		// Forward mode: one pass per variable gives a column of the Jacobian
+		var seed = make([]float64, nVars)
+		for j := 0; j < nVars; j++ {
+			seed[j] = 1
+			var _, outputs = record(fn, x, seed)
+			for k, out := range outputs {
+				backing[k*nVars+j] = out.dualValue().dot
+			}
+			seed[j] = 0
+		}
+	} else {
+		// Reverse mode: one sweep per objective gives a row of the Jacobian
+		for k, out := range outputs {
+			for j, adj := range t.reverseSweep(out, nVars) {
+				backing[k*nVars+j] = adj.val
+			}
+		}
+	}
+	return tensor.New(tensor.WithShape(nDims, nVars), tensor.WithBacking(backing))
+}
+
+// adHessian Differentiates an ADFunction twice, R^M -> T(N, M, M).
+// The forward pass seeded with the j-th variable, followed by a reverse sweep
+// of the k-th objective, gives the j-th column of the Hessian of the k-th objective.
+func adHessian(fn ADFunction, x []float64) *tensor.Dense {
+	var nVars = len(x)
+	var seed = make([]float64, nVars)
+	var backing []float64
+	var nDims int
+	for j := 0; j < nVars; j++ {
+		seed[j] = 1
+		var t, outputs = record(fn, x, seed)
+		if backing == nil {
+			nDims = len(outputs)
+			backing = make([]float64, nDims*nVars*nVars)
+		}
+		for k, out := range outputs {
+			for i, adj := range t.reverseSweep(out, nVars) {
+				backing[(k*nVars+i)*nVars+j] = adj.dot
+			}
+		}
+		seed[j] = 0
+	}
+	return tensor.New(tensor.WithShape(nDims, nVars, nVars), tensor.WithBacking(backing))
+}
+
+// newProblemFromAD Builds a Problem whose objectives are an ADFunction:
+// its Jacobian and Hessians are obtained by automatic differentiation.
+func newProblemFromAD(nVars, nDims int, fn ADFunction) Problem {
+	return Problem{nVars: nVars, nDims: nDims, ad: fn}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// adTestEquations All the ADVar operations (see adTestFunction), in 4 objectives of 3 variables,
+// written as equations.
+var adTestEquations = []string{
+	"(x*y - z/2)**3 + sin(x)*cos(z)",
+	"exp(-x*y) + log(1+z*z) - sqrt(x*x+y*y+1)",
+	"tanh(x-y) * atan(z) + abs(x-2*y) - (-z)",
+	"(1+x*x)**(y/3) + 2*x + 3",
+}
+
+func adTestFunction(t *Tape, v []ADVar) []ADVar {
+	var x, y, z = v[0], v[1], v[2]
+	var f1 = x.mul(y).sub(z.div(t.constant(2))).powConst(3).add(x.sin().mul(z.cos()))
+	var f2 = x.mul(y).neg().exp().add(z.mul(z).addConst(1).log()).sub(x.mul(x).add(y.mul(y)).addConst(1).sqrt())
+	var f3 = x.sub(y).tanh().mul(z.atan()).add(x.sub(y.mulConst(2)).abs()).sub(z.neg())
+	var f4 = x.mul(x).addConst(1).pow(y.div(t.constant(3))).add(x.mulConst(2)).addConst(3)
+	return []ADVar{f1, f2, f3, f4}
+}
+
+func TestAutomaticDifferentiation(t *testing.T) {
+	var symbolic = mustProblemFromEquations(adTestEquations, "x", "y", "z")
+	var points = [][]float64{{0.3, 0.8, 1.1}, {-0.9, 0.2, -1.7}, {1.1, -1.4, 0.6}}
+
+	// 4 objectives of 3 variables: forward mode, then the first 2 only: reverse mode
+	for _, nDims := range []int{4, 2} {
+		var fn ADFunction = func(t *Tape, v []ADVar) []ADVar { return adTestFunction(t, v)[:nDims] }
+		var pb = newProblemFromAD(3, nDims, fn)
+		for _, x := range points {
+			var compare = func(what string, got, want []float64) {
+				for i := range want[:len(got)] {
+					if !closeTo(got[i], want[i], 1e-10) {
+						t.Errorf("%d objectives, %s entry %d at %g: %g, want %g", nDims, what, i, x, got[i], want[i])
+					}
+				}
+			}
+			compare("value", pb.values(x), symbolic.values(x))
+			compare("Jacobian", pb.jacobian(x).Data().([]float64), symbolic.jacobian(x).Data().([]float64))
+			compare("Hessian", pb.hessian(x).Data().([]float64), symbolic.hessian(x).Data().([]float64))
+			if report := CheckDerivatives(&pb, x); !report.ok() {
+				t.Errorf("%d objectives: %v", nDims, report)
+			}
+		}
+	}
+}
+
+func TestAutomaticDifferentiationClosedForm(t *testing.T) {
+	// Rosenbrock: f = (1-x)² + 100 (y-x²)²
+	var pb = newProblemFromAD(2, 1, func(t *Tape, v []ADVar) []ADVar {
+		var a = t.constant(1).sub(v[0])
+		var b = v[1].sub(v[0].mul(v[0]))
+		return []ADVar{a.mul(a).add(b.mul(b).mulConst(100))}
+	})
+	var x, y = -1.2, 1.0
+	var gradient = []float64{-2*(1-x) - 400*x*(y-x*x), 200 * (y - x*x)}
+	var hessian = []float64{2 - 400*(y-3*x*x), -400 * x, -400 * x, 200}
+	for i, got := range pb.jacobian([]float64{x, y}).Data().([]float64) {
+		if !closeTo(got, gradient[i], 1e-12) {
+			t.Errorf("gradient entry %d: %g, want %g", i, got, gradient[i])
+		}
+	}
+	for i, got := range pb.hessian([]float64{x, y}).Data().([]float64) {
+		if !closeTo(got, hessian[i], 1e-12) {
+			t.Errorf("Hessian entry %d: %g, want %g", i, got, hessian[i])
+		}
+	}
+	if got, want := pb.values([]float64{x, y})[0], math.Pow(1-x, 2)+100*math.Pow(y-x*x, 2); !closeTo(got, want, 1e-12) {
+		t.Errorf("value %g, want %g", got, want)
+	}
+}
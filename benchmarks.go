@@ -0,0 +1,556 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"gorgonia.org/tensor"
+)
+
+// ##############################################################
+// Multiobjective benchmark problems from the literature, with
+// analytic Jacobians and samples of their true Pareto fronts.
+//
+// References:
+//  - Zitzler, E., Deb, K., Thiele, L.: Comparison of Multiobjective
+//    Evolutionary Algorithms: Empirical Results. Evolutionary
+//    Computation 8(2) (2000), 173-195
+//  - Deb, K., Thiele, L., Laumanns, M., Zitzler, E.: Scalable Test
+//    Problems for Evolutionary Multiobjective Optimization. In
+//    Evolutionary Multiobjective Optimization, Springer (2005), 105-145
+// ##############################################################
+
+// Benchmark A test Problem with its known Pareto front
+type Benchmark struct {
+	Problem
+	name  string
+	front func(nPoints int) [][]float64 // about nPoints samples of the true Pareto front, in the objective space
+}
+
+// ##############################################################
+// ZDT problems: 2 objectives, f1(x1) and f2 = g(x2..xn) * h(f1, g)
+// ##############################################################
+
+// zdtParts The three functions defining a ZDT problem, with their derivatives
+type zdtParts struct {
+	f1 func(x1 float64) (float64, float64)              // value and derivative
+	g  func(rest []float64) (float64, []float64)        // value and gradient, on x2..xn
+	h  func(f1, g float64) (value, dhdf1, dhdg float64) // value and partial derivatives
+}
+
+func zdtIdentity(x1 float64) (float64, float64) { return x1, 1 }
+
+// zdtLinearG g = 1 + 9 * mean(x2..xn)
+func zdtLinearG(rest []float64) (float64, []float64) {
+	var g, c = 1.0, 9.0 / float64(len(rest))
+	var grad = make([]float64, len(rest))
+	for i, xi := range rest {
+		g += c * xi
+		grad[i] = c
+	}
+	return g, grad
+}
+
+// zdtConvexH h = 1 - sqrt(f1/g), whose front is convex
+func zdtConvexH(f1, g float64) (float64, float64, float64) {
+	return 1 - math.Sqrt(f1/g), -0.5 / math.Sqrt(f1*g), 0.5 * math.Sqrt(f1) / math.Pow(g, 1.5)
+}
+
+// zdtConcaveH h = 1 - (f1/g)², whose front is concave
+func zdtConcaveH(f1, g float64) (float64, float64, float64) {
+	return 1 - (f1/g)*(f1/g), -2 * f1 / (g * g), 2 * f1 * f1 / (g * g * g)
+}
+
+// curveFront Samples the front f2 = curve(f1) for f1 in [from, to],
+// keeping only the non-dominated samples.
+func curveFront(from, to float64, curve func(float64) float64) func(int) [][]float64 {
+	return func(nPoints int) [][]float64 {
+		var front = make([][]float64, nPoints)
+		for i := range front {
+			var f1 = from
+			if nPoints > 1 {
+				f1 += (to - from) * float64(i) / float64(nPoints-1)
+			}
+			front[i] = []float64{f1, curve(f1)}
+		}
+		return nonDominated(front)
+	}
+}
+
+// newZDT Creates the ZDT problem of the given number (1 to 6), with nVars variables
+// (usually 30 for ZDT1-3, 10 for ZDT4 and ZDT6, and 11 for ZDT5).
+// The gradients of f2 are infinite where f1 = 0 (and for ZDT6 where g is minimal).
+// ZDT5 is defined on bit strings: here its variables are the relaxed numbers of
+// ones u1 in [0, 30] and ui in [0, 5] of the substrings, and v(ui) = 2 + ui if ui < 5,
+// 1 if ui = 5, so it is only piecewise differentiable.
+func newZDT(number, nVars int) Benchmark {
+	if nVars < 2 {
+		panic("the ZDT problems need at least 2 variables")
+	}
+	var lower, upper = make([]float64, nVars), make([]float64, nVars)
+	for i := range upper {
+		upper[i] = 1
+	}
+	var parts zdtParts
+	var front func(int) [][]float64
+	switch number {
+	case 1:
+		parts = zdtParts{zdtIdentity, zdtLinearG, zdtConvexH}
+		front = curveFront(0, 1, func(f1 float64) float64 { return 1 - math.Sqrt(f1) })
+	case 2:
+		parts = zdtParts{zdtIdentity, zdtLinearG, zdtConcaveH}
+		front = curveFront(0, 1, func(f1 float64) float64 { return 1 - f1*f1 })
+	case 3:
+		parts = zdtParts{zdtIdentity, zdtLinearG, func(f1, g float64) (float64, float64, float64) {
+			var s, c = math.Sin(10 * math.Pi * f1), math.Cos(10 * math.Pi * f1)
+			return 1 - math.Sqrt(f1/g) - f1/g*s,
+				-0.5/math.Sqrt(f1*g) - s/g - 10*math.Pi*f1/g*c,
+				0.5*math.Sqrt(f1)/math.Pow(g, 1.5) + f1*s/(g*g)
+		}}
+		front = curveFront(0, 1, func(f1 float64) float64 { return 1 - math.Sqrt(f1) - f1*math.Sin(10*math.Pi*f1) })
+	case 4:
+		for i := 1; i < nVars; i++ {
+			lower[i], upper[i] = -5, 5
+		}
+		parts = zdtParts{zdtIdentity, func(rest []float64) (float64, []float64) {
+			var g = 1 + 10*float64(len(rest))
+			var grad = make([]float64, len(rest))
+			for i, xi := range rest {
+				g += xi*xi - 10*math.Cos(4*math.Pi*xi)
+				grad[i] = 2*xi + 40*math.Pi*math.Sin(4*math.Pi*xi)
+			}
+			return g, grad
+		}, zdtConvexH}
+		front = curveFront(0, 1, func(f1 float64) float64 { return 1 - math.Sqrt(f1) })
+	case 5:
+		upper[0] = 30
+		for i := 1; i < nVars; i++ {
+			upper[i] = 5
+		}
+		parts = zdtParts{func(u1 float64) (float64, float64) { return 1 + u1, 1 },
+			func(rest []float64) (float64, []float64) {
+				var g = 0.0
+				var grad = make([]float64, len(rest))
+				for i, ui := range rest {
+					if ui < 5 {
+						g += 2 + ui
+						grad[i] = 1
+					} else {
+						g++
+					}
+				}
+				return g, grad
+			},
+			func(f1, g float64) (float64, float64, float64) { return 1 / f1, -1 / (f1 * f1), 0 }}
+		front = func(int) [][]float64 {
+			var front = make([][]float64, 31)
+			for u1 := range front {
+				front[u1] = []float64{float64(1 + u1), float64(nVars-1) / float64(1+u1)}
+			}
+			return front
+		}
+	case 6:
+		parts = zdtParts{func(x1 float64) (float64, float64) {
+			var e, s, c = math.Exp(-4 * x1), math.Sin(6 * math.Pi * x1), math.Cos(6 * math.Pi * x1)
+			var s5 = s * s * s * s * s
+			return 1 - e*s5*s, e * s5 * (4*s - 36*math.Pi*c)
+		}, func(rest []float64) (float64, []float64) {
+			var n = float64(len(rest))
+			var mean = 0.0
+			for _, xi := range rest {
+				mean += xi / n
+			}
+			var grad = make([]float64, len(rest))
+			for i := range grad {
+				grad[i] = 9 * 0.25 * math.Pow(mean, -0.75) / n
+			}
+			return 1 + 9*math.Pow(mean, 0.25), grad
+		}, zdtConcaveH}
+		front = curveFront(0.2807753191, 1, func(f1 float64) float64 { return 1 - f1*f1 })
+	default:
+		panic(fmt.Sprintf("there is no ZDT%d problem", number))
+	}
+
+	var f = func(x []float64) *tensor.Dense {
+		var f1, _ = parts.f1(x[0])
+		var g, _ = parts.g(x[1:])
+		var h, _, _ = parts.h(f1, g)
+		return tensor.New(tensor.WithShape(2, 1), tensor.WithBacking([]float64{f1, g * h}))
+	}
+	var jacobian = func(x []float64) *tensor.Dense {
+		var f1, df1 = parts.f1(x[0])
+		var g, dg = parts.g(x[1:])
+		var h, dhdf1, dhdg = parts.h(f1, g)
+		var backing = make([]float64, 2*nVars)
+		backing[0] = df1
+		backing[nVars] = g * dhdf1 * df1
+		for i := range dg {
+			backing[nVars+1+i] = (h + g*dhdg) * dg[i]
+		}
+		return tensor.New(tensor.WithShape(2, nVars), tensor.WithBacking(backing))
+	}
+	var pb = Problem{nVars: nVars, nDims: 2, f: f, jacobianf: jacobian}
+	return Benchmark{pb.withBounds(lower, upper), fmt.Sprintf("ZDT%d", number), front}
+}
+
+// ##############################################################
+// DTLZ problems: M objectives, x = (x1..x(M-1), xM) where the position
+// variables x1..x(M-1) place the point on the front and the distance
+// variables xM (k of them) control g(xM), 0 on the front.
+// ##############################################################
+
+// simplexLattice Returns all the vectors of m non-negative multiples of 1/h whose sum is 1.
+// These are the Das and Dennis structured points of the unit simplex.
+func simplexLattice(m, h int) [][]float64 {
+	var points = [][]float64{}
+	var current = make([]int, m)
+	var fill func(k, left int)
+	fill = func(k, left int) {
+		if k == m-1 {
+			current[k] = left
+			var pt = make([]float64, m)
+			for i, c := range current {
+				pt[i] = float64(c) / float64(h)
+			}
+			points = append(points, pt)
+			return
+		}
+		for c := 0; c <= left; c++ {
+			current[k] = c
+			fill(k+1, left-c)
+		}
+	}
+	fill(0, h)
+	return points
+}
+
+// latticeDivisions The smallest h such that simplexLattice(m, h) has at least nPoints points
+func latticeDivisions(m, nPoints int) int {
+	for h := 1; ; h++ {
+		// the lattice has C(h+m-1, m-1) points
+		var count = 1.0
+		for i := 1; i < m; i++ {
+			count = count * float64(h+i) / float64(i)
+		}
+		if count >= float64(nPoints) {
+			return h
+		}
+	}
+}
+
+// productGradient Returns the product of the factors, and its partial derivatives
+// with respect to each factor (computed without divisions, to allow zeros).
+func productGradient(factors []float64) (float64, []float64) {
+	var n = len(factors)
+	var partials = make([]float64, n)
+	var prefix = 1.0
+	for t := 0; t < n; t++ {
+		partials[t] = prefix
+		prefix *= factors[t]
+	}
+	var suffix = 1.0
+	for t := n - 1; t >= 0; t-- {
+		partials[t] *= suffix
+		suffix *= factors[t]
+	}
+	return prefix, partials
+}
+
+// dtlzRastriginG The multimodal g of DTLZ1 and DTLZ3
+func dtlzRastriginG(xm []float64) (float64, []float64) {
+	var g = float64(len(xm))
+	var grad = make([]float64, len(xm))
+	for i, xi := range xm {
+		var y = xi - 0.5
+		g += y*y - math.Cos(20*math.Pi*y)
+		grad[i] = 100 * (2*y + 20*math.Pi*math.Sin(20*math.Pi*y))
+	}
+	return 100 * g, grad
+}
+
+// dtlzSphereG The unimodal g of DTLZ2, DTLZ4 and DTLZ5
+func dtlzSphereG(xm []float64) (float64, []float64) {
+	var g = 0.0
+	var grad = make([]float64, len(xm))
+	for i, xi := range xm {
+		g += (xi - 0.5) * (xi - 0.5)
+		grad[i] = 2 * (xi - 0.5)
+	}
+	return g, grad
+}
+
+// newDTLZ Creates the DTLZ problem of the given number (1 to 7), with nObjectives objectives
+// and nVars variables. If nVars is 0, the usual k = nVars - nObjectives + 1 distance variables
+// are used: 5 for DTLZ1, 20 for DTLZ7 and 10 for the others.
+// DTLZ6 has infinite gradients where a distance variable is 0.
+func newDTLZ(number, nObjectives, nVars int) Benchmark {
+	var m = nObjectives
+	if nVars == 0 {
+		switch number {
+		case 1:
+			nVars = m + 4
+		case 7:
+			nVars = m + 19
+		default:
+			nVars = m + 9
+		}
+	}
+	if m < 2 || nVars < m {
+		panic(fmt.Sprintf("DTLZ problems need at least 2 objectives and as many variables (got %d and %d)", m, nVars))
+	}
+	var lower, upper = make([]float64, nVars), make([]float64, nVars)
+	for i := range upper {
+		upper[i] = 1
+	}
+	var name = fmt.Sprintf("DTLZ%d", number)
+
+	if number == 7 {
+		var pb = dtlz7(m, nVars)
+		return Benchmark{pb.withBounds(lower, upper), name, dtlz7Front(m)}
+	}
+
+	// DTLZ1 to 6: f_j = scale(g) * product of factors of the positions.
+	// Linear (DTLZ1): factors x_i and (1 - x_i), scale = (1 + g)/2.
+	// Spherical (DTLZ2-6): factors cos(theta_i) and sin(theta_i), scale = 1 + g.
+	var linear = number == 1
+	var gFunction func([]float64) (float64, []float64)
+	// theta Returns the angle of a position variable, and its derivatives with respect to it and to g
+	var theta func(i int, xi, g float64) (float64, float64, float64)
+	var plainTheta = func(i int, xi, g float64) (float64, float64, float64) { return xi * math.Pi / 2, math.Pi / 2, 0 }
+	var degenerateTheta = func(i int, xi, g float64) (float64, float64, float64) {
+		if i == 0 {
+			return xi * math.Pi / 2, math.Pi / 2, 0
+		}
+		return math.Pi / (4 * (1 + g)) * (1 + 2*g*xi), math.Pi * g / (2 * (1 + g)), math.Pi / 4 * (2*xi - 1) / ((1 + g) * (1 + g))
+	}
+	var front func(int) [][]float64
+	switch number {
+	case 1:
+		gFunction = dtlzRastriginG
+		front = func(nPoints int) [][]float64 {
+			var front = simplexLattice(m, latticeDivisions(m, nPoints))
+			for _, pt := range front {
+				for j := range pt {
+					pt[j] *= 0.5
+				}
+			}
+			return front
+		}
+	case 2, 3, 4:
+		gFunction = dtlzSphereG
+		if number == 3 {
+			gFunction = dtlzRastriginG
+		}
+		theta = plainTheta
+		if number == 4 {
+			const alpha = 100.0
+			theta = func(i int, xi, g float64) (float64, float64, float64) {
+				return math.Pow(xi, alpha) * math.Pi / 2, alpha * math.Pow(xi, alpha-1) * math.Pi / 2, 0
+			}
+		}
+		front = func(nPoints int) [][]float64 {
+			var front = simplexLattice(m, latticeDivisions(m, nPoints))
+			for _, pt := range front {
+				var norm = 0.0
+				for _, fj := range pt {
+					norm += fj * fj
+				}
+				for j := range pt {
+					pt[j] /= math.Sqrt(norm)
+				}
+			}
+			return front
+		}
+	case 5, 6:
+		gFunction = dtlzSphereG
+		if number == 6 {
+			gFunction = func(xm []float64) (float64, []float64) {
+				var g = 0.0
+				var grad = make([]float64, len(xm))
+				for i, xi := range xm {
+					g += math.Pow(xi, 0.1)
+					grad[i] = 0.1 * math.Pow(xi, -0.9)
+				}
+				return g, grad
+			}
+		}
+		theta = degenerateTheta
+		front = func(nPoints int) [][]float64 {
+			// A curve: theta_0 is free, the other angles are pi/4 when g = 0
+			var front = make([][]float64, nPoints)
+			for p := range front {
+				var angles = make([]float64, m-1)
+				for i := range angles {
+					angles[i] = math.Pi / 4
+				}
+				if nPoints > 1 {
+					angles[0] = math.Pi / 2 * float64(p) / float64(nPoints-1)
+				}
+				front[p] = make([]float64, m)
+				for j := 0; j < m; j++ {
+					front[p][j] = 1
+					for i := 0; i < m-1-j; i++ {
+						front[p][j] *= math.Cos(angles[i])
+					}
+					if j > 0 {
+						front[p][j] *= math.Sin(angles[m-1-j])
+					}
+				}
+			}
+			return front
+		}
+	default:
+		panic(fmt.Sprintf("there is no DTLZ%d problem", number))
+	}
+
+	// evaluate Computes the objectives, and the Jacobian if jac is not nil
+	var evaluate = func(x []float64, jac []float64) []float64 {
+		var g, dg = gFunction(x[m-1:])
+		var scale, dscale = 1 + g, 1.0
+		if linear {
+			scale, dscale = 0.5*(1+g), 0.5
+		}
+		var angles, dAngle, dAngledg []float64 // theta_i and its derivatives with respect to x_i and g
+		if !linear {
+			angles, dAngle, dAngledg = make([]float64, m-1), make([]float64, m-1), make([]float64, m-1)
+			for i := 0; i < m-1; i++ {
+				angles[i], dAngle[i], dAngledg[i] = theta(i, x[i], g)
+			}
+		}
+
+		var images = make([]float64, m)
+		for j := 0; j < m; j++ {
+			// the factors of f_j, each depending on the position variable of the same index,
+			// and their derivatives with respect to it (or to its angle)
+			var nFactors = m - 1 - j
+			if j > 0 {
+				nFactors++
+			}
+			var factors, dFactors = make([]float64, nFactors), make([]float64, nFactors)
+			for i := 0; i < nFactors; i++ {
+				var last = j > 0 && i == nFactors-1
+				switch {
+				case linear && !last:
+					factors[i], dFactors[i] = x[i], 1
+				case linear && last:
+					factors[i], dFactors[i] = 1-x[i], -1
+				case !last:
+					factors[i], dFactors[i] = math.Cos(angles[i]), -math.Sin(angles[i])
+				default:
+					factors[i], dFactors[i] = math.Sin(angles[i]), math.Cos(angles[i])
+				}
+			}
+			var prod, dprod = productGradient(factors)
+			images[j] = scale * prod
+			if jac == nil {
+				continue
+			}
+
+			var row = jac[j*nVars : (j+1)*nVars]
+			var dfdg = dscale * prod // through the scale
+			for i := 0; i < nFactors; i++ {
+				var dfdFactor = scale * dprod[i] * dFactors[i]
+				if linear {
+					row[i] += dfdFactor
+				} else {
+					row[i] += dfdFactor * dAngle[i]
+					dfdg += dfdFactor * dAngledg[i] // through the angles of DTLZ5 and DTLZ6
+				}
+			}
+			for d := range dg {
+				row[m-1+d] += dfdg * dg[d]
+			}
+		}
+		return images
+	}
+
+	var f = func(x []float64) *tensor.Dense {
+		return tensor.New(tensor.WithShape(m, 1), tensor.WithBacking(evaluate(x, nil)))
+	}
+	var jacobian = func(x []float64) *tensor.Dense {
+		var backing = make([]float64, m*nVars)
+		evaluate(x, backing)
+		return tensor.New(tensor.WithShape(m, nVars), tensor.WithBacking(backing))
+	}
+	var pb = Problem{nVars: nVars, nDims: m, f: f, jacobianf: jacobian}
+	return Benchmark{pb.withBounds(lower, upper), name, front}
+}
+
+// dtlz7 The disconnected DTLZ7 problem: f_j = x_j for j < M, and
+// f_M = (1 + g) * (M - sum_j f_j / (1 + g) * (1 + sin(3 pi f_j))), with g = 1 + 9 * mean(xM)
+func dtlz7(m, nVars int) Problem {
+	var k = float64(nVars - m + 1)
+	var evaluate = func(x []float64, jac []float64) []float64 {
+		var g = 1.0
+		for _, xi := range x[m-1:] {
+			g += 9 * xi / k
+		}
+		var images = make([]float64, m)
+		var h = float64(m)
+		var dhdg = 0.0
+		for j := 0; j < m-1; j++ {
+			images[j] = x[j]
+			var s = 1 + math.Sin(3*math.Pi*x[j])
+			h -= x[j] / (1 + g) * s
+			dhdg += x[j] / ((1 + g) * (1 + g)) * s
+			if jac != nil {
+				jac[j*nVars+j] = 1
+				jac[(m-1)*nVars+j] = -(s + 3*math.Pi*x[j]*math.Cos(3*math.Pi*x[j]))
+			}
+		}
+		images[m-1] = (1 + g) * h
+		if jac != nil {
+			for i := m - 1; i < nVars; i++ {
+				jac[(m-1)*nVars+i] = 9 / k * (h + (1+g)*dhdg)
+			}
+		}
+		return images
+	}
+	return Problem{
+		nVars: nVars,
+		nDims: m,
+		f: func(x []float64) *tensor.Dense {
+			return tensor.New(tensor.WithShape(m, 1), tensor.WithBacking(evaluate(x, nil)))
+		},
+		jacobianf: func(x []float64) *tensor.Dense {
+			var backing = make([]float64, m*nVars)
+			evaluate(x, backing)
+			return tensor.New(tensor.WithShape(m, nVars), tensor.WithBacking(backing))
+		},
+	}
+}
+
+// dtlz7Front Samples the disconnected front of DTLZ7: a regular grid of the positions
+// with g = 1, keeping only the non-dominated points.
+func dtlz7Front(m int) func(int) [][]float64 {
+	return func(nPoints int) [][]float64 {
+		var perAxis = int(math.Ceil(math.Pow(float64(nPoints), 1/float64(m-1))))
+		if perAxis < 2 {
+			perAxis = 2
+		}
+		var samples = [][]float64{}
+		var index = make([]int, m-1)
+		for {
+			var pt = make([]float64, m)
+			var h = float64(m)
+			for j := 0; j < m-1; j++ {
+				pt[j] = float64(index[j]) / float64(perAxis-1)
+				h -= pt[j] / 2 * (1 + math.Sin(3*math.Pi*pt[j]))
+			}
+			pt[m-1] = 2 * h
+			samples = append(samples, pt)
+
+			// next index of the grid
+			var j = 0
+			for j < m-1 && index[j] == perAxis-1 {
+				index[j] = 0
+				j++
+			}
+			if j == m-1 {
+				break
+			}
+			index[j]++
+		}
+		return nonDominated(samples)
+	}
+}
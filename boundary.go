@@ -0,0 +1,176 @@
+package main
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+	"gorgonia.org/tensor"
+)
+
+// ##############################################################
+// Normal Boundary Intersection and Pascoletti-Serafini scalarizations:
+// the front is searched along parallel lines, from evenly spread points of
+// the convex hull of the individual minima (CHIM) toward the ideal point.
+// Unlike the weighted sums, they also find the non-convex parts of the front.
+// Their subproblems have constraints, and an extra variable t (the distance
+// along the line): they are solved with the augmented Lagrangian method.
+//
+// References:
+//  - Das, I., Dennis, J.E.: Normal-Boundary Intersection: A New Method for
+//    Generating the Pareto Surface in Nonlinear Multicriteria Optimization
+//    Problems. SIAM Journal on Optimization 8(3) (1998), 631-657
+//  - Pascoletti, A., Serafini, P.: Scalarizing Vector Optimization Problems.
+//    Journal of Optimization Theory and Applications 42(4) (1984), 499-524
+// ##############################################################
+
+// CHIM : The convex hull of the individual minima, the points F* + Phi beta for beta on the unit
+// simplex, F* being the ideal point and the column j of Phi being F(x*_j) - F*.
+type CHIM struct {
+	minima []Point    // x*_j, the minimum of the objective j
+	ideal  []float64  // F*
+	payoff *mat.Dense // Phi, the shifted payoff matrix
+	normal []float64  // -Phi e, normal to the CHIM (for 2 objectives), toward the ideal point
+}
+
+// newCHIM Computes the individual minima from the starting point (see payoffTable), and the CHIM
+func newCHIM(p *Problem, start []float64, newOptimizer func(start *Point) Optimizer) *CHIM {
+	var m = p.nDims
+	var c = &CHIM{minima: payoffTable(p, start, newOptimizer)}
+	c.ideal, _ = idealAndNadir(c.minima)
+	c.payoff = mat.NewDense(m, m, nil)
+	c.normal = make([]float64, m)
+	for j := range c.minima {
+		for k, fk := range imagesOf(&c.minima[j]) {
+			c.payoff.Set(k, j, fk-c.ideal[k])
+			c.normal[k] -= fk - c.ideal[k]
+		}
+	}
+	return c
+}
+
+// point The point F* + Phi beta of the CHIM
+func (c *CHIM) point(beta []float64) []float64 {
+	var a = mat.NewVecDense(len(c.ideal), nil)
+	a.MulVec(c.payoff, mat.NewVecDense(len(beta), beta))
+	return floats.AddTo(make([]float64, len(c.ideal)), c.ideal, a.RawVector().Data)
+}
+
+// lineProblem The single-objective problem in the variables (x, t) maximizing t subject to
+// F(x) = a + t d (equality) or F(x) <= a + t d, added to the bounds and constraints of p
+// (t is not bounded).
+func (p *Problem) lineProblem(a, d []float64, equality bool) *Problem {
+	var n, m = p.nVars, p.nDims
+	var q = Problem{nVars: n + 1, nDims: 1, numdiff: p.numdiff}
+	q.f = func(y []float64) *tensor.Dense {
+		return tensor.New(tensor.WithShape(1, 1), tensor.WithBacking([]float64{-y[n]}))
+	}
+	q.jacobianf = func(y []float64) *tensor.Dense {
+		var grad = make([]float64, n+1)
+		grad[n] = -1
+		return tensor.New(tensor.WithShape(1, n+1), tensor.WithBacking(grad))
+	}
+	q.hessianf = func(y []float64) *tensor.Dense {
+		return tensor.New(tensor.WithShape(1, n+1, n+1), tensor.WithBacking(make([]float64, (n+1)*(n+1))))
+	}
+	if p.isBounded() {
+		q = q.withBounds(append(append([]float64{}, p.lower...), math.Inf(-1)), append(append([]float64{}, p.upper...), math.Inf(1)))
+	}
+
+	// F(x) - a - t d, and its Jacobian
+	var line = func(y []float64) []float64 {
+		var c = floats.SubTo(make([]float64, m), p.values(y[:n]), a)
+		floats.AddScaled(c, -y[n], d)
+		return c
+	}
+	var lineJacobian = func(y []float64) []float64 {
+		var jac = p.jacobian(y[:n]).Data().([]float64)
+		var rows = make([]float64, 0, m*(n+1))
+		for k := 0; k < m; k++ {
+			rows = append(append(rows, jac[k*n:(k+1)*n]...), -d[k])
+		}
+		return rows
+	}
+
+	// the constraints of p, in the variables (x, t), followed by the line constraints if they are of the same kind
+	var extended = func(count int, c, jacobian func([]float64) *tensor.Dense, withLine bool) (int, func([]float64) *tensor.Dense, func([]float64) *tensor.Dense) {
+		var total = count
+		if withLine {
+			total += m
+		}
+		var values = func(y []float64) *tensor.Dense {
+			var v = []float64{}
+			if count > 0 {
+				v = append(v, c(y[:n]).Data().([]float64)...)
+			}
+			if withLine {
+				v = append(v, line(y)...)
+			}
+			return tensor.New(tensor.WithShape(total, 1), tensor.WithBacking(v))
+		}
+		var rows = func(y []float64) *tensor.Dense {
+			var r = make([]float64, 0, total*(n+1))
+			if count > 0 {
+				var jac = constraintJacobian(count, c, jacobian, y[:n]).Data().([]float64)
+				for i := 0; i < count; i++ {
+					r = append(append(r, jac[i*n:(i+1)*n]...), 0)
+				}
+			}
+			if withLine {
+				r = append(r, lineJacobian(y)...)
+			}
+			return tensor.New(tensor.WithShape(total, n+1), tensor.WithBacking(r))
+		}
+		return total, values, rows
+	}
+	if p.nIneq > 0 || !equality {
+		q = q.withInequalities(extended(p.nIneq, p.ineq, p.ineqJacobian, !equality))
+	}
+	if p.nEq > 0 || equality {
+		q = q.withEqualities(extended(p.nEq, p.eq, p.eqJacobian, equality))
+	}
+	return &q
+}
+
+// boundarySweep Solves the line problems from the points of the simplex lattice of the CHIM with
+// the given number of divisions, along its normal, starting from the solution of the closest point.
+func boundarySweep(p *Problem, start []float64, divisions int, equality bool, newOptimizer func(start *Point) Optimizer) []Point {
+	var n = p.nVars
+	var chim = newCHIM(p, start, newOptimizer)
+	var d = chim.normal
+	var betas = simplexLattice(p.nDims, divisions) // the first one is (0, ..., 0, 1)
+	return sweep(p, chim.minima[p.nDims-1].inputs, betas, func(beta, x []float64) []float64 {
+		var a = chim.point(beta)
+
+		// the starting t: the closest point of the line for NBI, the largest feasible t for Pascoletti-Serafini
+		var shift = floats.SubTo(make([]float64, len(a)), p.values(x), a)
+		var t = floats.Dot(shift, d) / floats.Dot(d, d)
+		if !equality {
+			t = math.Inf(1)
+			for k := range d {
+				if d[k] < 0 {
+					t = math.Min(t, shift[k]/d[k])
+				}
+			}
+		}
+		if math.IsNaN(t) || math.IsInf(t, 0) {
+			t = 0 // degenerate CHIM
+		}
+		var y = solveSubproblem(p.lineProblem(a, d, equality), append(append([]float64{}, x...), t), newOptimizer)
+		return append([]float64{}, y[:n]...)
+	})
+}
+
+// normalBoundaryIntersection Approximates the front with the NBI method: for every point of the CHIM
+// (taken on its simplex lattice), the farthest point of the image of the domain along the normal
+// to the CHIM. The solutions are evenly spread, but not always Pareto optimal (where the front folds).
+func normalBoundaryIntersection(p *Problem, start []float64, divisions int, newOptimizer func(start *Point) Optimizer) []Point {
+	return boundarySweep(p, start, divisions, true, newOptimizer)
+}
+
+// pascolettiSerafini Approximates the front with the Pascoletti-Serafini scalarization: for every
+// point a of the CHIM (taken on its simplex lattice), the farthest t such that some F(x) is below
+// a + t d, d being the normal to the CHIM. The solutions are weakly Pareto optimal.
+func pascolettiSerafini(p *Problem, start []float64, divisions int, newOptimizer func(start *Point) Optimizer) []Point {
+	return boundarySweep(p, start, divisions, false, newOptimizer)
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// ##############################################################
+// Box constraints: lower[i] <= x[i] <= upper[i] for every variable.
+// A Problem without bounds (nil slices) is unconstrained, and
+// infinite bounds can be used for the unbounded variables.
+// ##############################################################
+
+// withBounds Returns a copy of the problem restricted to the box [lower, upper]
+func (p Problem) withBounds(lower, upper []float64) Problem {
+	if len(lower) != p.nVars || len(upper) != p.nVars {
+		panic(fmt.Sprintf("%d variables but %d lower and %d upper bounds", p.nVars, len(lower), len(upper)))
+	}
+	for i := range lower {
+		if lower[i] > upper[i] {
+			panic(fmt.Sprintf("empty domain for variable %d: [%g, %g]", i+1, lower[i], upper[i]))
+		}
+	}
+	p.lower, p.upper = lower, upper
+	return p
+}
+
+// isBounded Tells if the problem has box constraints
+func (p *Problem) isBounded() bool {
+	return p.lower != nil
+}
+
+// isFeasible Tells if x is in the box of the problem
+func (p *Problem) isFeasible(x []float64) bool {
+	if !p.isBounded() {
+		return true
+	}
+	for i := range x {
+		if x[i] < p.lower[i] || x[i] > p.upper[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// project Returns the closest point to x in the box of the problem
+func (p *Problem) project(x []float64) []float64 {
+	var y = make([]float64, len(x))
+	copy(y, x)
+	if !p.isBounded() {
+		return y
+	}
+	for i := range y {
+		y[i] = math.Min(math.Max(y[i], p.lower[i]), p.upper[i])
+	}
+	return y
+}
+
+// startingPoint Evaluates a starting point for an optimizer. If it is not feasible,
+// it is either projected on the box, or rejected with an error.
+func (p *Problem) startingPoint(x []float64, projectIfInfeasible bool) (Point, error) {
+	if !p.isFeasible(x) {
+		if !projectIfInfeasible {
+			return Point{}, fmt.Errorf("starting point %v is outside of the domain [%v, %v]", x, p.lower, p.upper)
+		}
+		x = p.project(x)
+	}
+	return p.evaluate(x), nil
+}
+
+// projectedGradientNorm The norm of P(x - grad fk(x)) - x, which replaces the gradient norm
+// of the objective k as a stationarity measure when the variables are bounded.
+func projectedGradientNorm(pt *Point, k int) float64 {
+	if !pt.Problem.isBounded() {
+		norm, _ := pt.gradNorm.At(k)
+		return norm.(float64)
+	}
+	var x = pt.inputs
+	var step = make([]float64, len(x))
+	for i := range x {
+		g, _ := pt.gradient.At(k, i)
+		step[i] = x[i] - g.(float64)
+	}
+	step = pt.Problem.project(step)
+	var norm = 0.0
+	for i := range x {
+		norm += (step[i] - x[i]) * (step[i] - x[i])
+	}
+	return math.Sqrt(norm)
+}
+
+// plotRange The gnuplot range of a variable: its bounds if they are finite, [-5:5] otherwise.
+func (p *Problem) plotRange(i int) string {
+	if p.isBounded() && !math.IsInf(p.lower[i], 0) && !math.IsInf(p.upper[i], 0) {
+		return fmt.Sprintf("[%g:%g]", p.lower[i], p.upper[i])
+	}
+	return "[-5:5]"
+}
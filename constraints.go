@@ -0,0 +1,282 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"gorgonia.org/tensor"
+)
+
+// ##############################################################
+// General constraints g(x) <= 0 and h(x) = 0 of a Problem, and the
+// augmented Lagrangian method to solve the constrained problems.
+// ##############################################################
+
+// withInequalities Returns a copy of the problem with the n constraints g(x) <= 0,
+// g being R^M -> R^n and its Jacobian R^M -> M(n, M). jacobian can be nil,
+// it is then approximated numerically.
+func (p Problem) withInequalities(n int, g, jacobian func([]float64) *tensor.Dense) Problem {
+	p.nIneq, p.ineq, p.ineqJacobian = n, g, jacobian
+	return p
+}
+
+// withEqualities Returns a copy of the problem with the n constraints h(x) = 0,
+// h being R^M -> R^n and its Jacobian R^M -> M(n, M). jacobian can be nil,
+// it is then approximated numerically.
+func (p Problem) withEqualities(n int, h, jacobian func([]float64) *tensor.Dense) Problem {
+	p.nEq, p.eq, p.eqJacobian = n, h, jacobian
+	return p
+}
+
+// constraintsFromEquations Compiles constraints written as equations (gnuplot syntax),
+// and derives their Jacobian symbolically. Pass them to withInequalities or withEqualities.
+func constraintsFromEquations(equations []string, variables ...string) (func([]float64) *tensor.Dense, func([]float64) *tensor.Dense, error) {
+	nodes, err := parseEquations(equations, variables)
+	if err != nil {
+		return nil, nil, err
+	}
+	return compileNodes(nodes), compileJacobian(symbolicJacobian(nodes, len(variables))), nil
+}
+
+// isConstrained Tells if the problem has other constraints than its bounds
+func (p *Problem) isConstrained() bool {
+	return p.nIneq+p.nEq > 0
+}
+
+// constraintJacobian The Jacobian of a constraint function, or its numerical approximation
+func constraintJacobian(n int, c, jacobian func([]float64) *tensor.Dense, x []float64) *tensor.Dense {
+	if jacobian != nil {
+		return jacobian(x)
+	}
+	var asProblem = Problem{nVars: len(x), nDims: n, f: c}
+	return asProblem.numericalJacobian(x)
+}
+
+// constraintValues The values of g(x) and h(x), nil if there are none
+func (p *Problem) constraintValues(x []float64) ([]float64, []float64) {
+	var g, h []float64
+	if p.nIneq > 0 {
+		g = p.ineq(x).Data().([]float64)
+	}
+	if p.nEq > 0 {
+		h = p.eq(x).Data().([]float64)
+	}
+	return g, h
+}
+
+// constraintViolation The largest violation of the constraints at x:
+// max(max_i max(0, g_i(x)), max_j |h_j(x)|), 0 if x is feasible.
+func (p *Problem) constraintViolation(x []float64) float64 {
+	var g, h = p.constraintValues(x)
+	var violation = 0.0
+	for _, gi := range g {
+		violation = math.Max(violation, gi)
+	}
+	for _, hj := range h {
+		violation = math.Max(violation, math.Abs(hj))
+	}
+	return violation
+}
+
+// ##############################################################
+// Augmented Lagrangian optimizer
+// ##############################################################
+
+// AugmentedLagrangian : A multiobjective augmented Lagrangian method. At every move,
+// the same Powell-Hestenes-Rockafellar penalty term is added to all the objectives,
+// and this unconstrained (except for the bounds) subproblem is solved by an inner
+// optimizer. Then the multipliers are updated, and the penalty increased if the
+// constraint violation did not decrease enough.
+type AugmentedLagrangian struct {
+	current       *Point                       // starting point, on the constrained problem
+	inner         func(start *Point) Optimizer // builds the optimizer of a subproblem from its starting point
+	tolerance     float64                      // max constraint violation and min step to continue iterating
+	maxit         uint                         // max number of outer iterations before halt
+	penalty       float64                      // rho, the weight of the penalty term
+	penaltyGrowth float64                      // factor applied to rho when the violation does not decrease enough
+	lambda        []float64                    // multipliers of the inequality constraints
+	mu            []float64                    // multipliers of the equality constraints
+	lastStep      float64                      // norm of the last move in the space of variables
+}
+
+// newAugmentedLagrangian Creates an AugmentedLagrangian optimizer with null multipliers
+// and the usual initial penalty.
+func newAugmentedLagrangian(start *Point, inner func(start *Point) Optimizer, tolerance float64, maxit uint) *AugmentedLagrangian {
+	return &AugmentedLagrangian{
+		current:       start,
+		inner:         inner,
+		tolerance:     tolerance,
+		maxit:         maxit,
+		penalty:       10,
+		penaltyGrowth: 10,
+		lambda:        make([]float64, start.Problem.nIneq),
+		mu:            make([]float64, start.Problem.nEq),
+		lastStep:      math.Inf(1),
+	}
+}
+
+// subproblem Builds the unconstrained problem whose objectives are the augmented Lagrangians
+//
+//	L_k(x) = f_k(x) + 1/(2 rho) sum_i (max(0, lambda_i + rho g_i(x))² - lambda_i²) + sum_j (mu_j h_j(x) + rho/2 h_j(x)²)
+//
+// Its Hessians use the Gauss-Newton approximation of the penalty term (the curvature of
+// the constraints is neglected).
+func (o *AugmentedLagrangian) subproblem() *Problem {
+	var pb = o.current.Problem
+	var rho = o.penalty
+	var lambda = append([]float64{}, o.lambda...)
+	var mu = append([]float64{}, o.mu...)
+
+	// The penalty value, and the coefficients of the constraint gradients in its gradient
+	var penalty = func(x []float64) (float64, []float64, []float64) {
+		var g, h = pb.constraintValues(x)
+		var value = 0.0
+		var cg = make([]float64, len(g))
+		var ch = make([]float64, len(h))
+		for i, gi := range g {
+			cg[i] = math.Max(0, lambda[i]+rho*gi)
+			value += (cg[i]*cg[i] - lambda[i]*lambda[i]) / (2 * rho)
+		}
+		for j, hj := range h {
+			ch[j] = mu[j] + rho*hj
+			value += mu[j]*hj + 0.5*rho*hj*hj
+		}
+		return value, cg, ch
+	}
+
+	// The Jacobians of the constraints, as rows of length nVars
+	var rows = func(x []float64) ([]float64, []float64) {
+		var jg, jh []float64
+		if pb.nIneq > 0 {
+			jg = constraintJacobian(pb.nIneq, pb.ineq, pb.ineqJacobian, x).Data().([]float64)
+		}
+		if pb.nEq > 0 {
+			jh = constraintJacobian(pb.nEq, pb.eq, pb.eqJacobian, x).Data().([]float64)
+		}
+		return jg, jh
+	}
+
+	var sub = Problem{
+		nVars:     pb.nVars,
+		nDims:     pb.nDims,
+		equations: pb.equations,
+		lower:     pb.lower,
+		upper:     pb.upper,
+		numdiff:   pb.numdiff,
+	}
+	sub.f = func(x []float64) *tensor.Dense {
+		var images = append([]float64{}, pb.values(x)...)
+		var value, _, _ = penalty(x)
+		for k := range images {
+			images[k] += value
+		}
+		return tensor.New(tensor.WithShape(pb.nDims, 1), tensor.WithBacking(images))
+	}
+	sub.jacobianf = func(x []float64) *tensor.Dense {
+		var n = pb.nVars
+		var jac = append([]float64{}, pb.jacobian(x).Data().([]float64)...)
+		var _, cg, ch = penalty(x)
+		var jg, jh = rows(x)
+		var grad = make([]float64, n)
+		for i, c := range cg {
+			for v := 0; v < n; v++ {
+				grad[v] += c * jg[i*n+v]
+			}
+		}
+		for j, c := range ch {
+			for v := 0; v < n; v++ {
+				grad[v] += c * jh[j*n+v]
+			}
+		}
+		for k := 0; k < pb.nDims; k++ {
+			for v := 0; v < n; v++ {
+				jac[k*n+v] += grad[v]
+			}
+		}
+		return tensor.New(tensor.WithShape(pb.nDims, n), tensor.WithBacking(jac))
+	}
+	sub.hessianf = func(x []float64) *tensor.Dense {
+		var n = pb.nVars
+		var hess = append([]float64{}, pb.hessian(x).Data().([]float64)...)
+		var _, cg, _ = penalty(x)
+		var jg, jh = rows(x)
+		var gn = make([]float64, n*n) // rho * sum of the outer products of the active constraint gradients
+		var addOuter = func(row []float64) {
+			for a := 0; a < n; a++ {
+				for b := 0; b < n; b++ {
+					gn[a*n+b] += rho * row[a] * row[b]
+				}
+			}
+		}
+		for i, c := range cg {
+			if c > 0 {
+				addOuter(jg[i*n : (i+1)*n])
+			}
+		}
+		for j := 0; j < pb.nEq; j++ {
+			addOuter(jh[j*n : (j+1)*n])
+		}
+		for k := 0; k < pb.nDims; k++ {
+			for ab := range gn {
+				hess[k*n*n+ab] += gn[ab]
+			}
+		}
+		return tensor.New(tensor.WithShape(pb.nDims, n, n), tensor.WithBacking(hess))
+	}
+	return &sub
+}
+
+func (o *AugmentedLagrangian) move(current *Point) Point {
+	var pb = current.Problem
+	var before = current.violation
+
+	// Solve the subproblem, starting from the current point
+	var sub = o.subproblem()
+	var start = sub.evaluate(current.inputs)
+	var opt = o.inner(&start)
+	descend(opt)
+	var pt = pb.evaluate(opt.getCurrent().inputs)
+
+	// Update the multipliers with the constraint values at the solution
+	var g, h = pb.constraintValues(pt.inputs)
+	for i, gi := range g {
+		o.lambda[i] = math.Max(0, o.lambda[i]+o.penalty*gi)
+	}
+	for j, hj := range h {
+		o.mu[j] += o.penalty * hj
+	}
+	if pt.violation > 0.25*before {
+		o.penalty *= o.penaltyGrowth
+	}
+
+	var step = 0.0
+	for i := range pt.inputs {
+		step += (pt.inputs[i] - current.inputs[i]) * (pt.inputs[i] - current.inputs[i])
+	}
+	o.lastStep = math.Sqrt(step)
+	fmt.Printf("Augmented Lagrangian: constraint violation %g, penalty %g\n", pt.violation, o.penalty)
+
+	o.current = &pt
+	return pt
+}
+
+func (o *AugmentedLagrangian) getCurrent() *Point {
+	return o.current
+}
+
+func (o *AugmentedLagrangian) checkConverged(itNumber uint) bool {
+
+	// Check if we ran for too long
+	if itNumber > o.maxit {
+		fmt.Printf("Stopping without convergence after %d iterations. =(\n", o.maxit)
+		return true
+	}
+
+	// Check if the point is feasible and does not move anymore
+	if o.current.violation <= o.tolerance && o.lastStep <= o.tolerance {
+		fmt.Printf("Constraint violation %g and step %g are below the tolerance threshold (%f), let's stop, we converged!\n", o.current.violation, o.lastStep, o.tolerance)
+		return true
+	}
+
+	return false
+}
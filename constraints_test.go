@@ -0,0 +1,57 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAugmentedLagrangianOnConstrainedExample(t *testing.T) {
+	// the unit disk cuts the segment of the Pareto set between (-1, -1) and (1, 1): each objective
+	// alone is minimized on the circle, at ±(1/√2, 1/√2), where its multiplier is √2 - 1
+	p, starts, err := loadProblemFile("problems/constrained_example.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var corner = 1 / math.Sqrt2
+	for _, start := range starts {
+		for k, want := range [][]float64{{corner, corner}, {-corner, -corner}} {
+			var k = k
+			first, err := p.startingPoint(start, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var o = newAugmentedLagrangian(&first, func(s *Point) Optimizer {
+				return &MonoGradientDescent{s, k, 1e-8, 10000, false, 1.0, newArmijoBacktracking()}
+			}, 1e-8, 100)
+			descend(o)
+			var end = o.getCurrent()
+			var g, _ = p.constraintValues(end.inputs)
+			if !closeTo(end.inputs[0], want[0], 1e-6) || !closeTo(end.inputs[1], want[1], 1e-6) {
+				t.Errorf("objective %d from %v: solution %v, want %v", k, start, end.inputs, want)
+			}
+			if !(math.Abs(g[0]) <= 1e-6) {
+				t.Errorf("objective %d from %v: constraint %g, want it active", k, start, g[0])
+			}
+			if !(end.violation <= 1e-8) {
+				t.Errorf("objective %d from %v: final violation %g", k, start, end.violation)
+			}
+			if !closeTo(o.lambda[0], math.Sqrt2-1, 1e-6) {
+				t.Errorf("objective %d from %v: multiplier %g, want %g", k, start, o.lambda[0], math.Sqrt2-1)
+			}
+		}
+
+		// the steepest descent stops inside the disk, on the Pareto set, where the constraint is inactive
+		first, _ := p.startingPoint(start, true)
+		var o = newAugmentedLagrangian(&first, func(s *Point) Optimizer {
+			return &SteepestDescent{s, 1e-8, 10000, false, 1.0, newArmijoBacktracking()}
+		}, 1e-8, 100)
+		descend(o)
+		var end = o.getCurrent()
+		if end.violation != 0 || o.lambda[0] != 0 {
+			t.Errorf("steepest descent from %v: violation %g and multiplier %g, want 0", start, end.violation, o.lambda[0])
+		}
+		if !closeTo(end.inputs[0], end.inputs[1], 1e-6) || !(math.Abs(end.inputs[0]) <= corner) {
+			t.Errorf("steepest descent from %v: %v is not on the Pareto set", start, end.inputs)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"math"
+	"sort"
+)
+
+// ##############################################################
+// Pareto dominance between vectors of objective values
+// ##############################################################
+
+// dominates Tells if the objective vector a Pareto-dominates b:
+// a is lower or equal on all the objectives, and strictly lower on one.
+func dominates(a, b []float64) bool {
+	var strictly = false
+	for k := range a {
+		if a[k] > b[k] {
+			return false
+		}
+		if a[k] < b[k] {
+			strictly = true
+		}
+	}
+	return strictly
+}
+
+// nonDominated Returns the vectors of the set which are not dominated by another one.
+func nonDominated(set [][]float64) [][]float64 {
+	var front = [][]float64{}
+	for i, a := range set {
+		var dominated = false
+		for j, b := range set {
+			if i != j && dominates(b, a) {
+				dominated = true
+				break
+			}
+		}
+		if !dominated {
+			front = append(front, a)
+		}
+	}
+	return front
+}
+
+// imagesOf Returns the objective values of a point as a slice
+func imagesOf(pt *Point) []float64 {
+	return pt.images.Data().([]float64)
+}
+
+// imagesOfPoints The objective values of each point, without copy
+func imagesOfPoints(points []Point) [][]float64 {
+	var images = make([][]float64, len(points))
+	for a := range points {
+		images[a] = imagesOf(&points[a])
+	}
+	return images
+}
+
+// constrainedDominates Deb's constrained domination: a feasible point dominates the infeasible
+// ones, an infeasible point dominates those with a larger constraint violation, and two
+// feasible points are compared with the Pareto dominance.
+func constrainedDominates(a, b *Point) bool {
+	switch {
+	case a.violation == 0 && b.violation == 0:
+		return dominates(imagesOf(a), imagesOf(b))
+	case a.violation == 0:
+		return true
+	case b.violation == 0:
+		return false
+	}
+	return a.violation < b.violation
+}
+
+// nonDominatedSort Deb's fast non-dominated sorting: splits the population into fronts of
+// indices, the first front being the non-dominated points, the second those dominated only
+// by the first front, etc. (with the constrained domination).
+func nonDominatedSort(population []Point) [][]int {
+	var n = len(population)
+	var dominatedBy = make([]int, n) // number of points dominating each point
+	var dominating = make([][]int, n)
+	var fronts = [][]int{{}}
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			if constrainedDominates(&population[i], &population[j]) {
+				dominating[i] = append(dominating[i], j)
+				dominatedBy[j]++
+			} else if constrainedDominates(&population[j], &population[i]) {
+				dominating[j] = append(dominating[j], i)
+				dominatedBy[i]++
+			}
+		}
+	}
+	for i := 0; i < n; i++ {
+		if dominatedBy[i] == 0 {
+			fronts[0] = append(fronts[0], i)
+		}
+	}
+	for f := 0; len(fronts[f]) > 0; f++ {
+		var next = []int{}
+		for _, i := range fronts[f] {
+			for _, j := range dominating[i] {
+				dominatedBy[j]--
+				if dominatedBy[j] == 0 {
+					next = append(next, j)
+				}
+			}
+		}
+		fronts = append(fronts, next)
+	}
+	return fronts[:len(fronts)-1] // the last one is empty
+}
+
+// crowdingDistance The crowding distance of the points of a front: the sum over the objectives
+// of the normalized distance between the two neighbours of each point. The extreme points
+// have an infinite distance, to be always kept.
+func crowdingDistance(population []Point, front []int) []float64 {
+	var distance = make([]float64, len(front))
+	if len(front) == 0 {
+		return distance
+	}
+	var order = make([]int, len(front)) // positions in front
+	for k := 0; k < population[front[0]].Problem.nDims; k++ {
+		var value = func(a int) float64 { return imagesOf(&population[front[order[a]]])[k] }
+		for a := range order {
+			order[a] = a
+		}
+		sort.Slice(order, func(a, b int) bool { return value(a) < value(b) })
+		var last = len(order) - 1
+		distance[order[0]], distance[order[last]] = math.Inf(1), math.Inf(1)
+		var span = value(last) - value(0)
+		if span == 0 {
+			continue
+		}
+		for a := 1; a < last; a++ {
+			distance[order[a]] += (value(a+1) - value(a-1)) / span
+		}
+	}
+	return distance
+}
+
+// nonDominatedPoints Returns the points of the population which are not dominated by another one
+// (with the constrained domination).
+func nonDominatedPoints(population []Point) []Point {
+	var front = []Point{}
+	if len(population) == 0 {
+		return front
+	}
+	for _, i := range nonDominatedSort(population)[0] {
+		front = append(front, population[i])
+	}
+	return front
+}
@@ -0,0 +1,463 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gorgonia.org/tensor"
+)
+
+// ##############################################################
+// Parse the gnuplot-syntax equations of a Problem, so that its
+// objective function can be built from the strings directly.
+// ##############################################################
+
+// exprNode A node of the syntax tree of a parsed equation.
+type exprNode interface {
+	eval(v []float64) float64
+	evalComplex(v []complex128) complex128 // for the complex-step derivatives
+	String() string
+}
+
+// constNode A numerical constant.
+type constNode struct {
+	value float64
+}
+
+// varNode A named variable, which reads the input vector at some index.
+type varNode struct {
+	index int
+	name  string
+}
+
+// unaryNode A unary minus applied to its argument.
+type unaryNode struct {
+	arg exprNode
+}
+
+// binaryNode An arithmetic operation between two sub-expressions.
+// op is one of '+', '-', '*', '/' or '^' (the gnuplot '**').
+type binaryNode struct {
+	op          byte
+	left, right exprNode
+}
+
+// callNode A call to one of the known math functions.
+type callNode struct {
+	name string
+	arg  exprNode
+}
+
+// The math functions which can be called in an equation.
+var exprFunctions = map[string]func(float64) float64{
+	"sin":   math.Sin,
+	"cos":   math.Cos,
+	"tan":   math.Tan,
+	"asin":  math.Asin,
+	"acos":  math.Acos,
+	"atan":  math.Atan,
+	"sinh":  math.Sinh,
+	"cosh":  math.Cosh,
+	"tanh":  math.Tanh,
+	"exp":   math.Exp,
+	"log":   math.Log,
+	"log10": math.Log10,
+	"sqrt":  math.Sqrt,
+	"abs":   math.Abs,
+}
+
+// Their analytic extensions to the complex plane. abs is extended as x*sign(Re(x)),
+// which gives the right derivative with the complex-step method. The implementations
+// of asin, acos and atan in cmplx lose the tiny imaginary parts of the complex step:
+// they are extended to first order instead (see firstOrder).
+var exprComplexFunctions = map[string]func(complex128) complex128{
+	"sin":   cmplx.Sin,
+	"cos":   cmplx.Cos,
+	"tan":   cmplx.Tan,
+	"asin":  firstOrder(math.Asin, func(x float64) float64 { return 1 / math.Sqrt(1-x*x) }),
+	"acos":  firstOrder(math.Acos, func(x float64) float64 { return -1 / math.Sqrt(1-x*x) }),
+	"atan":  firstOrder(math.Atan, func(x float64) float64 { return 1 / (1 + x*x) }),
+	"sinh":  cmplx.Sinh,
+	"cosh":  cmplx.Cosh,
+	"tanh":  cmplx.Tanh,
+	"exp":   cmplx.Exp,
+	"log":   cmplx.Log,
+	"log10": cmplx.Log10,
+	"sqrt":  cmplx.Sqrt,
+	"abs": func(z complex128) complex128 {
+		if real(z) < 0 {
+			return -z
+		}
+		return z
+	},
+}
+
+// firstOrder The extension f(a) + i b f'(a) of a real function f to z = a + ib,
+// exact to the first order in b, which is all the complex-step method needs.
+func firstOrder(f, derivative func(float64) float64) func(complex128) complex128 {
+	return func(z complex128) complex128 {
+		return complex(f(real(z)), imag(z)*derivative(real(z)))
+	}
+}
+
+// The named constants which can be used in an equation.
+var exprConstants = map[string]float64{
+	"pi": math.Pi,
+}
+
+func (n *constNode) eval(v []float64) float64 { return n.value }
+func (n *varNode) eval(v []float64) float64   { return v[n.index] }
+func (n *unaryNode) eval(v []float64) float64 { return -n.arg.eval(v) }
+func (n *callNode) eval(v []float64) float64  { return exprFunctions[n.name](n.arg.eval(v)) }
+
+func (n *binaryNode) eval(v []float64) float64 {
+	a, b := n.left.eval(v), n.right.eval(v)
+	switch n.op {
+	case '+':
+		return a + b
+	case '-':
+		return a - b
+	case '*':
+		return a * b
+	case '/':
+		return a / b
+	case '^':
+		return math.Pow(a, b)
+	}
+	panic(fmt.Sprintf("unknown operator %q", n.op))
+}
+
+func (n *constNode) evalComplex(v []complex128) complex128 { return complex(n.value, 0) }
+func (n *varNode) evalComplex(v []complex128) complex128   { return v[n.index] }
+func (n *unaryNode) evalComplex(v []complex128) complex128 { return -n.arg.evalComplex(v) }
+func (n *callNode) evalComplex(v []complex128) complex128 {
+	return exprComplexFunctions[n.name](n.arg.evalComplex(v))
+}
+
+func (n *binaryNode) evalComplex(v []complex128) complex128 {
+	a, b := n.left.evalComplex(v), n.right.evalComplex(v)
+	switch n.op {
+	case '+':
+		return a + b
+	case '-':
+		return a - b
+	case '*':
+		return a * b
+	case '/':
+		return a / b
+	case '^':
+		return cmplx.Pow(a, b)
+	}
+	panic(fmt.Sprintf("unknown operator %q", n.op))
+}
+
+func (n *constNode) String() string { return strconv.FormatFloat(n.value, 'g', -1, 64) }
+func (n *varNode) String() string   { return n.name }
+func (n *unaryNode) String() string { return "-(" + n.arg.String() + ")" }
+func (n *callNode) String() string  { return n.name + "(" + n.arg.String() + ")" }
+
+func (n *binaryNode) String() string {
+	var op = string(n.op)
+	if n.op == '^' {
+		op = "**"
+	}
+	return "(" + n.left.String() + op + n.right.String() + ")"
+}
+
+// exprParser A recursive descent parser for the gnuplot syntax:
+//
+//	expr    := term (('+' | '-') term)*
+//	term    := unary (('*' | '/') unary)*
+//	unary   := ('-' | '+') unary | power
+//	power   := primary ('**' unary)?
+//	primary := number | constant | variable | function '(' expr ')' | '(' expr ')'
+//
+// The equations are also plotted by gnuplot, so the forms it reads differently from most
+// languages are rejected: a minus before a power (gnuplot computes -x**2 as (-x)**2, write
+// -(x**2) or (-x)**2), and the division of two integers (gnuplot truncates 1/2 to 0, write 1.0/2).
+type exprParser struct {
+	input     string
+	pos       int
+	variables []string
+	power     bool              // if the last power parsed was not in parentheses
+	integers  map[exprNode]bool // the sub-expressions which are integers for gnuplot
+}
+
+// parseEquation Parses an equation written with the given variable names,
+// in the order in which they appear in the input vector.
+func parseEquation(equation string, variables []string) (exprNode, error) {
+	var ps = exprParser{input: equation, variables: variables, integers: map[exprNode]bool{}}
+	node, err := ps.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	ps.skipSpaces()
+	if ps.pos < len(ps.input) {
+		return nil, ps.errorf("unexpected %q", ps.input[ps.pos])
+	}
+	return node, nil
+}
+
+func (ps *exprParser) errorf(format string, a ...interface{}) error {
+	return fmt.Errorf("equation %q, column %d: %s", ps.input, ps.pos+1, fmt.Sprintf(format, a...))
+}
+
+func (ps *exprParser) skipSpaces() {
+	for ps.pos < len(ps.input) && unicode.IsSpace(rune(ps.input[ps.pos])) {
+		ps.pos++
+	}
+}
+
+// peek Returns the next non-space character, or 0 at the end of the input.
+func (ps *exprParser) peek() byte {
+	ps.skipSpaces()
+	if ps.pos < len(ps.input) {
+		return ps.input[ps.pos]
+	}
+	return 0
+}
+
+// peekPower Tells if the next token is the '**' operator.
+func (ps *exprParser) peekPower() bool {
+	ps.skipSpaces()
+	return strings.HasPrefix(ps.input[ps.pos:], "**")
+}
+
+func (ps *exprParser) parseExpr() (exprNode, error) {
+	left, err := ps.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for c := ps.peek(); c == '+' || c == '-'; c = ps.peek() {
+		ps.pos++
+		right, err := ps.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		left = ps.binary(c, left, right)
+	}
+	return left, nil
+}
+
+// binary Builds the operation, an integer if both operands are integers
+func (ps *exprParser) binary(op byte, left, right exprNode) exprNode {
+	var node = &binaryNode{op, left, right}
+	ps.integers[node] = ps.integers[left] && ps.integers[right]
+	return node
+}
+
+func (ps *exprParser) parseTerm() (exprNode, error) {
+	left, err := ps.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for c := ps.peek(); (c == '*' || c == '/') && !ps.peekPower(); c = ps.peek() {
+		var at = ps.pos
+		ps.pos++
+		right, err := ps.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if c == '/' && ps.integers[left] && ps.integers[right] {
+			ps.pos = at
+			return nil, ps.errorf("%s/%s divides two integers, which gnuplot truncates: write a real number in it (2.0 for 2)", left, right)
+		}
+		left = ps.binary(c, left, right)
+	}
+	return left, nil
+}
+
+func (ps *exprParser) parseUnary() (exprNode, error) {
+	switch ps.peek() {
+	case '-':
+		var at = ps.pos
+		ps.pos++
+		arg, err := ps.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if ps.power {
+			ps.pos = at
+			return nil, ps.errorf("'-' before a power is ambiguous, gnuplot computes (-a)**b: write -(a**b) or (-a)**b")
+		}
+		var node = &unaryNode{arg}
+		ps.integers[node] = ps.integers[arg]
+		return node, nil
+	case '+':
+		ps.pos++
+		return ps.parseUnary()
+	}
+	return ps.parsePower()
+}
+
+func (ps *exprParser) parsePower() (exprNode, error) {
+	base, err := ps.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	if ps.peekPower() {
+		ps.pos += 2
+		exponent, err := ps.parseUnary() // right associative: x**y**z is x**(y**z)
+		if err != nil {
+			return nil, err
+		}
+		ps.power = true
+		return ps.binary('^', base, exponent), nil
+	}
+	ps.power = false
+	return base, nil
+}
+
+func (ps *exprParser) parsePrimary() (exprNode, error) {
+	var c = ps.peek()
+	switch {
+	case c == 0:
+		return nil, ps.errorf("unexpected end of equation")
+	case c == '(':
+		ps.pos++
+		node, err := ps.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if ps.peek() != ')' {
+			return nil, ps.errorf("missing ')'")
+		}
+		ps.pos++
+		return node, nil
+	case c == '.' || (c >= '0' && c <= '9'):
+		return ps.parseNumber()
+	case c == '_' || unicode.IsLetter(rune(c)):
+		return ps.parseIdentifier()
+	}
+	return nil, ps.errorf("unexpected %q", c)
+}
+
+func (ps *exprParser) parseNumber() (exprNode, error) {
+	var start = ps.pos
+	for ps.pos < len(ps.input) {
+		c := ps.input[ps.pos]
+		if (c >= '0' && c <= '9') || c == '.' {
+			ps.pos++
+		} else if (c == 'e' || c == 'E') && ps.pos+1 < len(ps.input) {
+			// exponent part, with an optional sign
+			ps.pos++
+			if s := ps.input[ps.pos]; s == '+' || s == '-' {
+				ps.pos++
+			}
+		} else {
+			break
+		}
+	}
+	var text = ps.input[start:ps.pos]
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		ps.pos = start
+		return nil, ps.errorf("invalid number %q", text)
+	}
+	var node = &constNode{value}
+	ps.integers[node] = !strings.ContainsAny(text, ".eE") // gnuplot reads the other numbers as reals
+	return node, nil
+}
+
+func (ps *exprParser) parseIdentifier() (exprNode, error) {
+	var start = ps.pos
+	for ps.pos < len(ps.input) {
+		c := rune(ps.input[ps.pos])
+		if c != '_' && !unicode.IsLetter(c) && !unicode.IsDigit(c) {
+			break
+		}
+		ps.pos++
+	}
+	var name = ps.input[start:ps.pos]
+
+	// Function call
+	if ps.peek() == '(' {
+		if _, ok := exprFunctions[name]; !ok {
+			ps.pos = start
+			return nil, ps.errorf("unknown function %q", name)
+		}
+		ps.pos++
+		arg, err := ps.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if ps.peek() != ')' {
+			return nil, ps.errorf("missing ')' after the argument of %s", name)
+		}
+		ps.pos++
+		return &callNode{name, arg}, nil
+	}
+
+	// Variable or constant
+	for i, v := range ps.variables {
+		if v == name {
+			return &varNode{i, name}, nil
+		}
+	}
+	if value, ok := exprConstants[name]; ok {
+		return &constNode{value}, nil
+	}
+	ps.pos = start
+	return nil, ps.errorf("unknown variable %q", name)
+}
+
+// parseEquations Parses a list of equations sharing the same variables,
+// and simplifies them for a faster evaluation (see symbolic.go).
+func parseEquations(equations []string, variables []string) ([]exprNode, error) {
+	var nodes = make([]exprNode, len(equations))
+	for j, eq := range equations {
+		node, err := parseEquation(eq, variables)
+		if err != nil {
+			return nil, err
+		}
+		nodes[j] = simplify(node)
+	}
+	return nodes, nil
+}
+
+// compileEquations Builds the objective function of a problem, R^M -> R^N,
+// from its equations. variables gives the names used in the equations,
+// in the order of the input vector.
+func compileEquations(equations []string, variables []string) (func([]float64) *tensor.Dense, error) {
+	nodes, err := parseEquations(equations, variables)
+	if err != nil {
+		return nil, err
+	}
+	return compileNodes(nodes), nil
+}
+
+// compileNodes Turns parsed equations into the function R^M -> R^N of a Problem.
+func compileNodes(nodes []exprNode) func([]float64) *tensor.Dense {
+	return func(v []float64) *tensor.Dense {
+		var images = make([]float64, len(nodes))
+		for j, node := range nodes {
+			images[j] = node.eval(v)
+		}
+		return tensor.New(tensor.WithShape(len(nodes), 1), tensor.WithBacking(images))
+	}
+}
+
+// compileComplexNodes Same as compileNodes, but evaluated on complex inputs.
+func compileComplexNodes(nodes []exprNode) func([]complex128) []complex128 {
+	return func(v []complex128) []complex128 {
+		var images = make([]complex128, len(nodes))
+		for j, node := range nodes {
+			images[j] = node.evalComplex(v)
+		}
+		return images
+	}
+}
+
+// mustCompileEquations Like compileEquations, but panics if an equation
+// cannot be parsed. Meant for the problems declared as global variables.
+func mustCompileEquations(equations []string, variables ...string) func([]float64) *tensor.Dense {
+	f, err := compileEquations(equations, variables)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestParseEquation(t *testing.T) {
+	var variables = []string{"x", "y"}
+	var v = []float64{2, 3}
+	var cases = []struct {
+		equation string
+		want     float64
+	}{
+		{"x+y*2", 8},
+		{"(x+y)*2", 10},
+		{"x-y-1", -2},
+		{"x/y/2", 1. / 3},
+		{"1.0/2", 0.5},
+		{"1/2.", 0.5},
+		{"1e0/2", 0.5},
+		{"x/2", 1},
+		{"-(x**2)", -4},
+		{"(-x)**2", 4},
+		{"-x*y", -6},
+		{"2**3**2", 512},
+		{"x**-1", 0.5},
+		{"- -x", 2},
+		{"+x", 2},
+		{"x ** y", 8},
+		{"1.5e1 + .5 + 2E-1", 15.7},
+		{"sin(x)**2 + cos(x)**2", 1},
+		{"exp(log(y))", 3},
+		{"sqrt(abs(-x*8))", 4},
+		{"log10(1000)", 3},
+		{"atan(1)*4", math.Pi},
+		{"2*pi", 2 * math.Pi},
+		{"  x\t*\ty ", 6},
+	}
+	for _, c := range cases {
+		node, err := parseEquation(c.equation, variables)
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", c.equation, err)
+			continue
+		}
+		if got := node.eval(v); math.Abs(got-c.want) > 1e-12*math.Max(1, math.Abs(c.want)) {
+			t.Errorf("%q = %g, want %g", c.equation, got, c.want)
+		}
+		// the simplified expression must have the same value
+		if got := simplify(node).eval(v); math.Abs(got-c.want) > 1e-12*math.Max(1, math.Abs(c.want)) {
+			t.Errorf("simplified %q = %g, want %g", c.equation, got, c.want)
+		}
+	}
+}
+
+func TestParseEquationErrors(t *testing.T) {
+	var cases = []struct {
+		equation string
+		message  string
+	}{
+		{"", "unexpected end of equation"},
+		{"x+", "unexpected end of equation"},
+		{"(x+y", "missing ')'"},
+		{"sin(x", "missing ')' after the argument of sin"},
+		{"foo(x)", `unknown function "foo"`},
+		{"z*2", `unknown variable "z"`},
+		{"x y", `unexpected 'y'`},
+		{"x*#", `unexpected '#'`},
+		{"1.2.3", `invalid number "1.2.3"`},
+		// gnuplot reads these differently
+		{"1/2", "1/2 divides two integers"},
+		{"x+(1+2)/2", "(1+2)/2 divides two integers"},
+		{"-3/2*x", "-(3)/2 divides two integers"},
+		{"-x**2", "'-' before a power is ambiguous"},
+		{"- -x**2", "'-' before a power is ambiguous"},
+		{"x**-y**2", "'-' before a power is ambiguous"},
+	}
+	for _, c := range cases {
+		_, err := parseEquation(c.equation, []string{"x", "y"})
+		if err == nil {
+			t.Errorf("%q: no error, want %q", c.equation, c.message)
+			continue
+		}
+		if !strings.Contains(err.Error(), c.message) {
+			t.Errorf("%q: error %q, want %q", c.equation, err, c.message)
+		}
+	}
+}
+
+func TestCompileEquations(t *testing.T) {
+	f, err := compileEquations([]string{"x*y", "x-y", "z"}, []string{"x", "y", "z"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var images = f([]float64{2, 5, 7})
+	if shape := images.Shape(); shape[0] != 3 || shape[1] != 1 {
+		t.Fatalf("shape %v, want (3, 1)", shape)
+	}
+	var want = []float64{10, -3, 7}
+	for k, got := range images.Data().([]float64) {
+		if got != want[k] {
+			t.Errorf("objective %d = %g, want %g", k, got, want[k])
+		}
+	}
+
+	if _, err := compileEquations([]string{"x", "x+"}, []string{"x"}); err == nil {
+		t.Error("no error for an invalid second equation")
+	}
+}
+
+func TestComplexEvaluation(t *testing.T) {
+	// the complex-step derivative of every function must match its analytic derivative
+	var cases = []struct {
+		equation   string
+		derivative func(x float64) float64
+	}{
+		{"sin(x)", math.Cos},
+		{"exp(2*x)", func(x float64) float64 { return 2 * math.Exp(2*x) }},
+		{"log(x)", func(x float64) float64 { return 1 / x }},
+		{"sqrt(x)", func(x float64) float64 { return 0.5 / math.Sqrt(x) }},
+		{"abs(x-1)", func(x float64) float64 { return math.Copysign(1, x-1) }},
+		{"x**3", func(x float64) float64 { return 3 * x * x }},
+		{"tanh(x)", func(x float64) float64 { return 1 - math.Pow(math.Tanh(x), 2) }},
+		{"asin(x/3)", func(x float64) float64 { return 1 / math.Sqrt(9-x*x) }},
+		{"acos(x/3)", func(x float64) float64 { return -1 / math.Sqrt(9-x*x) }},
+		{"atan(x)", func(x float64) float64 { return 1 / (1 + x*x) }},
+	}
+	for _, c := range cases {
+		node, err := parseEquation(c.equation, []string{"x"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, x := range []float64{0.3, 2.5} {
+			var h = 1e-20
+			var got = imag(node.evalComplex([]complex128{complex(x, h)})) / h
+			if want := c.derivative(x); math.Abs(got-want) > 1e-12*math.Max(1, math.Abs(want)) {
+				t.Errorf("%q at %g: complex-step derivative %g, want %g", c.equation, x, got, want)
+			}
+		}
+	}
+}
@@ -11,5 +11,6 @@ require (
 	golang.org/x/exp v0.0.0-20201008143054-e3b2a7f2fdc7 // indirect
 	golang.org/x/tools v0.0.0-20201105220310-78b158585360 // indirect
 	gonum.org/v1/gonum v0.8.1
+	gopkg.in/yaml.v2 v2.4.0
 	gorgonia.org/tensor v0.9.14
 )
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/optimize/functions"
+	"gorgonia.org/tensor"
+)
+
+// ##############################################################
+// Adapter from the single-objective test functions of
+// gonum/optimize/functions to our Problem type.
+// ##############################################################
+
+// gonumFunction Any gonum test function: it must at least have a Func method,
+// and can also have a Grad and a Hess method.
+type gonumFunction interface {
+	Func(x []float64) float64
+}
+
+// The two forms of the Grad methods found in gonum/optimize/functions
+type gonumGradient interface {
+	Grad(grad, x []float64)
+}
+type gonumGradientReturning interface {
+	Grad(grad, x []float64) []float64
+}
+
+type gonumHessian interface {
+	Hess(dst *mat.SymDense, x []float64)
+}
+
+// newProblemFromGonum Wraps a gonum test function of nVars variables into a Problem
+// with one objective. Its gradient and Hessian are used if the function has them,
+// otherwise they are approximated numerically.
+func newProblemFromGonum(fn gonumFunction, nVars int) Problem {
+	var pb = Problem{
+		nVars: nVars,
+		nDims: 1,
+		f: func(x []float64) *tensor.Dense {
+			return tensor.New(tensor.WithShape(1, 1), tensor.WithBacking([]float64{fn.Func(x)}))
+		},
+	}
+
+	var grad func(grad, x []float64)
+	switch g := fn.(type) {
+	case gonumGradient:
+		grad = g.Grad
+	case gonumGradientReturning:
+		grad = func(dst, x []float64) { g.Grad(dst, x) }
+	}
+	if grad != nil {
+		pb.jacobianf = func(x []float64) *tensor.Dense {
+			var backing = make([]float64, nVars)
+			grad(backing, x)
+			return tensor.New(tensor.WithShape(1, nVars), tensor.WithBacking(backing))
+		}
+	}
+
+	if h, ok := fn.(gonumHessian); ok {
+		pb.hessianf = func(x []float64) *tensor.Dense {
+			var hess = mat.NewSymDense(nVars, nil)
+			h.Hess(hess, x)
+			var backing = make([]float64, nVars*nVars)
+			for i := 0; i < nVars; i++ {
+				for j := 0; j < nVars; j++ {
+					backing[i*nVars+j] = hess.At(i, j)
+				}
+			}
+			return tensor.New(tensor.WithShape(1, nVars, nVars), tensor.WithBacking(backing))
+		}
+	}
+	return pb
+}
+
+// gonumEntry A gonum test function, with its usual starting point (which gives its number of variables)
+type gonumEntry struct {
+	function gonumFunction
+	start    []float64
+}
+
+// gonumCatalogue The test functions of gonum/optimize/functions which need no parameter, with the
+// starting points of Moré, Garbow and Hillstrom (1981). The functions of variable dimension are
+// given a usual number of variables, use newProblemFromGonum directly to choose another one.
+// Beale is left out, its Hess is wrong in gonum v0.8.1: BealeProblem is the same function.
+var gonumCatalogue = map[string]gonumEntry{
+	"BiggsEXP2":                  {functions.BiggsEXP2{}, []float64{1, 2}},
+	"BiggsEXP3":                  {functions.BiggsEXP3{}, []float64{1, 2, 1}},
+	"BiggsEXP4":                  {functions.BiggsEXP4{}, []float64{1, 2, 1, 1}},
+	"BiggsEXP5":                  {functions.BiggsEXP5{}, []float64{1, 2, 1, 1, 1}},
+	"BiggsEXP6":                  {functions.BiggsEXP6{}, []float64{1, 2, 1, 1, 1, 1}},
+	"Box3D":                      {functions.Box3D{}, []float64{0, 10, 20}},
+	"BraninHoo":                  {functions.BraninHoo{}, []float64{0, 0}},
+	"BrownBadlyScaled":           {functions.BrownBadlyScaled{}, []float64{1, 1}},
+	"BrownAndDennis":             {functions.BrownAndDennis{}, []float64{25, 5, -5, -1}},
+	"ExtendedPowellSingular":     {functions.ExtendedPowellSingular{}, []float64{3, -1, 0, 3}},        // any multiple of 4
+	"ExtendedRosenbrock":         {functions.ExtendedRosenbrock{}, []float64{-1.2, 1, -1.2, 1, -1.2}}, // any number
+	"Rosenbrock":                 {functions.ExtendedRosenbrock{}, []float64{-1.2, 1}},
+	"Gaussian":                   {functions.Gaussian{}, []float64{0.4, 1, 0}},
+	"GulfResearchAndDevelopment": {functions.GulfResearchAndDevelopment{}, []float64{5, 2.5, 0.15}},
+	"HelicalValley":              {functions.HelicalValley{}, []float64{-1, 0, 0}},
+	"Linear":                     {functions.Linear{}, []float64{1, 1, 1, 1, 1}},               // any number
+	"PenaltyI":                   {functions.PenaltyI{}, []float64{1, 2, 3, 4}},                // any number
+	"PenaltyII":                  {functions.PenaltyII{}, []float64{0.5, 0.5, 0.5, 0.5}},       // any number
+	"Powell":                     {functions.ExtendedPowellSingular{}, []float64{3, -1, 0, 3}}, // Powell's singular function
+	"PowellBadlyScaled":          {functions.PowellBadlyScaled{}, []float64{0, 1}},
+	"Trigonometric":              {functions.Trigonometric{}, []float64{0.1, 0.1, 0.1, 0.1, 0.1, 0.1, 0.1, 0.1, 0.1, 0.1}},     // any number
+	"VariablyDimensioned":        {functions.VariablyDimensioned{}, []float64{0.9, 0.8, 0.7, 0.6, 0.5, 0.4, 0.3, 0.2, 0.1, 0}}, // any number
+	"Watson":                     {functions.Watson{}, []float64{0, 0, 0, 0, 0, 0}},                                            // 2 to 31
+	"Wood":                       {functions.Wood{}, []float64{-3, -1, -3, -1}},
+	"ConcaveRight":               {functions.ConcaveRight{}, []float64{0}},
+	"ConcaveLeft":                {functions.ConcaveLeft{}, []float64{0}},
+}
+
+// gonumProblem Returns the Problem of a function of the catalogue with its usual starting point,
+// and false if it does not exist
+func gonumProblem(name string) (Problem, []float64, bool) {
+	entry, ok := gonumCatalogue[name]
+	if !ok {
+		return Problem{}, nil, false
+	}
+	return newProblemFromGonum(entry.function, len(entry.start)), append([]float64{}, entry.start...), true
+}
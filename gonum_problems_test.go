@@ -0,0 +1,33 @@
+package main
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestGonumCatalogueDerivatives(t *testing.T) {
+	var names = make([]string, 0, len(gonumCatalogue))
+	for name := range gonumCatalogue {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var rng = rand.New(rand.NewSource(1))
+	for _, name := range names {
+		p, start, _ := gonumProblem(name)
+		// at the starting point, and around it
+		var points = [][]float64{start}
+		for a := 0; a < 3; a++ {
+			var x = append([]float64{}, start...)
+			for i := range x {
+				x[i] += 0.5 * rng.NormFloat64()
+			}
+			points = append(points, x)
+		}
+		for _, x := range points {
+			if report := CheckDerivatives(&p, x); !report.ok() {
+				t.Errorf("%s at %v:\n%s", name, x, report)
+			}
+		}
+	}
+}
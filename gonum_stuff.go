@@ -19,6 +19,8 @@ func solveMonoObjectiveProblem() {
 		Func: functions.ExtendedRosenbrock{}.Func,
 		Grad: functions.ExtendedRosenbrock{}.Grad,
 	}
+	// or scalarize a multiobjective Problem (see scalarizations.go), for instance:
+	// problem := augmentedTchebycheff(&p, []float64{0.5, 0.5}, []float64{-600, -300}, 0.001)
 
 	// #############################################
 	// Define a starting point
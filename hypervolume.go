@@ -0,0 +1,270 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+)
+
+// ##############################################################
+// Hypervolume indicator: the measure of the part of the objective space
+// dominated by a set of points and bounded by a reference point.
+// The larger, the better the set approximates the Pareto front.
+//
+// References:
+//  - Fonseca, C.M., Paquete, L., López-Ibáñez, M.: An Improved Dimension-Sweep
+//    Algorithm for the Hypervolume Indicator. IEEE CEC (2006), 1157-1163
+//  - While, L., Bradstreet, L., Barone, L.: A Fast Way of Calculating Exact
+//    Hypervolumes. IEEE Transactions on Evolutionary Computation 16(1) (2012), 86-95
+// ##############################################################
+
+// hypervolume The hypervolume of a set of objective vectors, relative to the reference point.
+// The points which do not strictly dominate the reference point do not count.
+func hypervolume(points [][]float64, reference []float64) float64 {
+	var inside = [][]float64{}
+	for _, f := range points {
+		if dominatesStrictly(f, reference) {
+			inside = append(inside, f)
+		}
+	}
+	if len(inside) == 0 {
+		return 0
+	}
+	switch len(reference) {
+	case 1:
+		var best = inside[0][0]
+		for _, f := range inside {
+			best = math.Min(best, f[0])
+		}
+		return reference[0] - best
+	case 2:
+		return hypervolume2D(inside, reference)
+	case 3:
+		return hypervolume3D(inside, reference)
+	}
+	return hypervolumeWFG(nonDominated(inside), reference)
+}
+
+// dominatesStrictly Tells if a is strictly lower than b on all the objectives
+func dominatesStrictly(a, b []float64) bool {
+	for k := range a {
+		if a[k] >= b[k] {
+			return false
+		}
+	}
+	return true
+}
+
+// sortedByObjective Returns a copy of the points, sorted by increasing value of the objective k
+func sortedByObjective(points [][]float64, k int) [][]float64 {
+	var sorted = append([][]float64{}, points...)
+	sort.Slice(sorted, func(a, b int) bool { return sorted[a][k] < sorted[b][k] })
+	return sorted
+}
+
+// hypervolume2D The area dominated by 2-objective points, in O(n log n):
+// sweep the points by increasing f1, each adding a rectangle below the previous ones.
+func hypervolume2D(points [][]float64, reference []float64) float64 {
+	var area, lowest = 0.0, reference[1]
+	for _, f := range sortedByObjective(points, 0) {
+		if f[1] < lowest {
+			area += (reference[0] - f[0]) * (lowest - f[1])
+			lowest = f[1]
+		}
+	}
+	return area
+}
+
+// hypervolume3D The volume dominated by 3-objective points, with a dimension sweep on f3:
+// the points are added by increasing f3 to a 2D front (sorted by f1, decreasing f2) whose
+// area is updated at every insertion, and the volume grows by this area times the height
+// of every slab between two consecutive values of f3.
+func hypervolume3D(points [][]float64, reference []float64) float64 {
+	var sorted = sortedByObjective(points, 2)
+	var front = [][]float64{} // non-dominated in (f1, f2), sorted by increasing f1
+	var volume, area = 0.0, 0.0
+	for a, q := range sorted {
+		// the first point of the front after q in f1
+		var next = sort.Search(len(front), func(i int) bool { return front[i][0] >= q[0] })
+		var height = reference[1] // f2 of the front just before q
+		if next > 0 {
+			height = front[next-1][1]
+		}
+		if height > q[1] {
+			// q is not dominated: add the area it dominates alone, and remove the points it dominates
+			var x, last = q[0], next
+			for last < len(front) && front[last][1] >= q[1] {
+				area += (front[last][0] - x) * (height - q[1])
+				x, height = front[last][0], front[last][1]
+				last++
+			}
+			var end = reference[0]
+			if last < len(front) {
+				end = front[last][0]
+			}
+			area += (end - x) * (height - q[1])
+			front = append(front[:next], append([][]float64{q}, front[last:]...)...)
+		}
+
+		var top = reference[2]
+		if a+1 < len(sorted) {
+			top = sorted[a+1][2]
+		}
+		volume += area * (top - q[2])
+	}
+	return volume
+}
+
+// hypervolumeWFG The hypervolume of non-dominated points with the WFG algorithm: the sum over the
+// points of their exclusive hypervolume relative to the following ones.
+func hypervolumeWFG(points [][]float64, reference []float64) float64 {
+	var sorted = sortedByObjective(points, len(reference)-1)
+	var volume = 0.0
+	for a, p := range sorted {
+		volume += exclusiveHypervolume(p, sorted[a+1:], reference)
+	}
+	return volume
+}
+
+// exclusiveHypervolume The hypervolume dominated by p and not by the other points: the box between
+// p and the reference point, minus the hypervolume of the other points limited to this box.
+func exclusiveHypervolume(p []float64, others [][]float64, reference []float64) float64 {
+	var box = 1.0
+	for k := range p {
+		box *= reference[k] - p[k]
+	}
+	var limited = make([][]float64, len(others))
+	for a, q := range others {
+		limited[a] = make([]float64, len(q))
+		for k := range q {
+			limited[a][k] = math.Max(p[k], q[k])
+		}
+	}
+	return box - hypervolume(limited, reference)
+}
+
+// hypervolumeContributions The hypervolume dominated by each point only (0 for the dominated points),
+// relative to the reference point.
+func hypervolumeContributions(points [][]float64, reference []float64) []float64 {
+	if len(reference) == 2 {
+		return hypervolumeContributions2D(points, reference)
+	}
+	var contributions = make([]float64, len(points))
+	for i, f := range points {
+		if dominatesStrictly(f, reference) {
+			var others = append(append([][]float64{}, points[:i]...), points[i+1:]...)
+			contributions[i] = exclusiveHypervolume(f, others, reference)
+		}
+	}
+	return contributions
+}
+
+// hypervolumeContributions2D The contributions for 2 objectives, in O(n log n): the points of the
+// front split the dominated area in rectangles, one per point, between its neighbours on the front.
+// The points it dominates, which are inside its rectangle, take their share back.
+func hypervolumeContributions2D(points [][]float64, reference []float64) []float64 {
+	var order = make([]int, len(points))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool {
+		var fa, fb = points[order[a]], points[order[b]]
+		return fa[0] < fb[0] || (fa[0] == fb[0] && fa[1] < fb[1])
+	})
+	var front = []int{} // by increasing f1 and decreasing f2
+	for _, i := range order {
+		if dominatesStrictly(points[i], reference) && (len(front) == 0 || points[i][1] < points[front[len(front)-1]][1]) {
+			front = append(front, i)
+		}
+	}
+
+	// the upper corner of the rectangle of each point of the front, and the points inside it
+	var corners = make([][]float64, len(front))
+	var inside = make([][][]float64, len(front))
+	for a := range front {
+		corners[a] = append([]float64{}, reference...)
+		if a+1 < len(front) {
+			corners[a][0] = points[front[a+1]][0]
+		}
+		if a > 0 {
+			corners[a][1] = points[front[a-1]][1]
+		}
+	}
+	for j, f := range points {
+		var a = sort.Search(len(front), func(b int) bool { return points[front[b]][0] > f[0] }) - 1
+		if a >= 0 && front[a] != j && f[1] >= points[front[a]][1] && dominatesStrictly(f, corners[a]) {
+			inside[a] = append(inside[a], f)
+		}
+	}
+
+	var contributions = make([]float64, len(points))
+	for a, i := range front {
+		var rectangle = (corners[a][0] - points[i][0]) * (corners[a][1] - points[i][1])
+		contributions[i] = rectangle - hypervolume2D(inside[a], corners[a])
+	}
+	return contributions
+}
+
+// pointsHypervolume The hypervolume of the objective values of points: a trajectory, a population...
+func pointsHypervolume(points []Point, reference []float64) float64 {
+	return hypervolume(imagesOfPoints(points), reference)
+}
+
+// hypervolume The hypervolume of the members of the archive
+func (a *ParetoArchive) hypervolume(reference []float64) float64 {
+	return pointsHypervolume(a.members, reference)
+}
+
+// referencePoint A reference point for sets of points with no natural one: the worst value of
+// every objective, plus margin times its range (or margin if all the values are equal).
+func referencePoint(points [][]float64, margin float64) []float64 {
+	var worst = append([]float64{}, points[0]...)
+	var best = append([]float64{}, points[0]...)
+	for _, f := range points {
+		for k := range f {
+			worst[k] = math.Max(worst[k], f[k])
+			best[k] = math.Min(best[k], f[k])
+		}
+	}
+	for k := range worst {
+		if worst[k] > best[k] {
+			worst[k] += margin * (worst[k] - best[k])
+		} else {
+			worst[k] += margin
+		}
+	}
+	return worst
+}
+
+// readObjectivesCSV Reads the objective values of the points written in a CSV file by pointsToCSV
+// or trajToCSV: the last nDims columns of every line.
+func readObjectivesCSV(path string, nDims int) ([][]float64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.Comma = ' '
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	var points = make([][]float64, len(records))
+	for l, record := range records {
+		if len(record) < nDims {
+			return nil, fmt.Errorf("%s:%d: %d columns, expected at least %d", path, l+1, len(record), nDims)
+		}
+		points[l] = make([]float64, nDims)
+		for k := range points[l] {
+			if points[l][k], err = strconv.ParseFloat(record[len(record)-nDims+k], 64); err != nil {
+				return nil, fmt.Errorf("%s:%d: %v", path, l+1, err)
+			}
+		}
+	}
+	return points, nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestHypervolume(t *testing.T) {
+	var cases = []struct {
+		name      string
+		points    [][]float64
+		reference []float64
+		want      float64
+	}{
+		{"empty", [][]float64{}, []float64{1, 1}, 0},
+		{"1D", [][]float64{{2}, {3}}, []float64{5}, 3},
+		// 3 steps of areas 3, 2 and 1
+		{"2D", [][]float64{{1, 3}, {2, 2}, {3, 1}}, []float64{4, 4}, 6},
+		{"2D dominated", [][]float64{{3, 3}, {1, 3}, {2, 2}, {2, 2}, {3, 1}}, []float64{4, 4}, 6},
+		{"2D outside", [][]float64{{1, 3}, {5, 0}, {4, 1}}, []float64{4, 4}, 3},
+		{"3D box", [][]float64{{1, 1, 1}}, []float64{2, 2, 2}, 1},
+		// 3 boxes of volume 2, intersecting in the same unit cube: 3*2 - 3 + 1
+		{"3D", [][]float64{{0, 1, 1}, {1, 0, 1}, {1, 1, 0}}, []float64{2, 2, 2}, 4},
+		{"3D dominated", [][]float64{{1, 1, 1}, {0, 1, 1}, {1, 0, 1}, {1, 1, 0}}, []float64{2, 2, 2}, 4},
+		// 4 boxes of volume 2, intersecting in the same unit cube: 4*2 - 6 + 4 - 1
+		{"4D", [][]float64{{0, 1, 1, 1}, {1, 0, 1, 1}, {1, 1, 0, 1}, {1, 1, 1, 0}}, []float64{2, 2, 2, 2}, 5},
+		{"4D dominated", [][]float64{{1, 1, 1, 1}, {0, 1, 1, 1}, {1, 0, 1, 1}, {1, 1, 0, 1}, {1, 1, 1, 0}}, []float64{2, 2, 2, 2}, 5},
+		{"5D box", [][]float64{{1, 2, 3, 4, 5}}, []float64{2, 3, 4, 5, 6}, 1},
+	}
+	for _, c := range cases {
+		if got := hypervolume(c.points, c.reference); !closeTo(got, c.want, 1e-12) {
+			t.Errorf("%s: hypervolume %g, want %g", c.name, got, c.want)
+		}
+	}
+}
+
+func TestHypervolumeContributions(t *testing.T) {
+	var cases = []struct {
+		name      string
+		points    [][]float64
+		reference []float64
+		want      []float64
+	}{
+		{"2D", [][]float64{{1, 3}, {2, 2}, {3, 1}}, []float64{4, 4}, []float64{1, 1, 1}},
+		// (2.5, 2.5) takes a square of 0.25 from the rectangle of (2, 2), and (3, 3) nothing
+		{"2D dominated", [][]float64{{1, 3}, {2, 2}, {3, 1}, {2.5, 2.5}, {3, 3}}, []float64{4, 4}, []float64{1, 0.75, 1, 0, 0}},
+		{"2D duplicates", [][]float64{{1, 2}, {1, 2}, {2, 1}}, []float64{3, 3}, []float64{0, 0, 1}},
+		{"2D outside", [][]float64{{1, 1}, {0, 3}}, []float64{3, 3}, []float64{4, 0}},
+		{"3D", [][]float64{{0, 1, 1}, {1, 0, 1}, {1, 1, 0}}, []float64{2, 2, 2}, []float64{1, 1, 1}},
+		{"3D dominated", [][]float64{{0, 1, 1}, {1, 0, 1}, {1, 1, 0}, {1, 1, 1}}, []float64{2, 2, 2}, []float64{1, 1, 1, 0}},
+		{"4D", [][]float64{{0, 1, 1, 1}, {1, 0, 1, 1}, {1, 1, 0, 1}, {1, 1, 1, 0}}, []float64{2, 2, 2, 2}, []float64{1, 1, 1, 1}},
+	}
+	for _, c := range cases {
+		var got = hypervolumeContributions(c.points, c.reference)
+		for i := range c.want {
+			if !closeTo(got[i], c.want[i], 1e-12) {
+				t.Errorf("%s: contribution of %v %g, want %g", c.name, c.points[i], got[i], c.want[i])
+			}
+		}
+	}
+}
+
+// randomFront Random points on the simplex where the objectives sum to 1, with dominated
+// points among them if dominated is true
+func randomFront(rng *rand.Rand, n, nDims int, dominated bool) [][]float64 {
+	var points = make([][]float64, n)
+	for a := range points {
+		points[a] = make([]float64, nDims)
+		var sum = 0.0
+		for k := range points[a] {
+			points[a][k] = rng.Float64()
+			sum += points[a][k]
+		}
+		var scale = 1.0
+		if dominated && a%3 == 0 {
+			scale = 1.3
+		}
+		for k := range points[a] {
+			points[a][k] *= scale / sum
+		}
+	}
+	return points
+}
+
+func TestHypervolumeSweepsMatchWFG(t *testing.T) {
+	var rng = rand.New(rand.NewSource(1))
+	for _, nDims := range []int{2, 3} {
+		for _, n := range []int{1, 2, 5, 30} {
+			var points = randomFront(rng, n, nDims, true)
+			var reference = referencePoint(points, 0.1)
+			var sweep = hypervolume(points, reference)
+			var wfg = hypervolumeWFG(nonDominated(points), reference)
+			if !closeTo(sweep, wfg, 1e-10) {
+				t.Errorf("%d objectives, %d points: sweep %g, WFG %g", nDims, n, sweep, wfg)
+			}
+		}
+	}
+}
+
+func TestHypervolumeContributionsMatchDefinition(t *testing.T) {
+	// the contribution of a point is the hypervolume lost without it
+	var rng = rand.New(rand.NewSource(2))
+	for _, nDims := range []int{2, 3, 4} {
+		var points = randomFront(rng, 20, nDims, true)
+		var reference = referencePoint(points, 0.1)
+		var total = hypervolume(points, reference)
+		for i, got := range hypervolumeContributions(points, reference) {
+			var others = append(append([][]float64{}, points[:i]...), points[i+1:]...)
+			if want := total - hypervolume(others, reference); !closeTo(got, want, 1e-10) {
+				t.Errorf("%d objectives: contribution of point %d %g, want %g", nDims, i, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+// ##############################################################
+// Quality indicators of an approximation of the Pareto front, relative
+// to a reference front (usually samples of the true front). All of them
+// work on objective values: use imagesOfPoints for sets of Points.
+//
+// References:
+//  - Van Veldhuizen, D.A., Lamont, G.B.: Multiobjective Evolutionary
+//    Algorithm Research: A History and Analysis. Technical report
+//    TR-98-03, Air Force Institute of Technology (1998)
+//  - Coello Coello, C.A., Reyes Sierra, M.: A Study of the Parallelization
+//    of a Coevolutionary Multi-objective Evolutionary Algorithm. MICAI (2004)
+//  - Ishibuchi, H., Masuda, H., Tanigaki, Y., Nojima, Y.: Modified Distance
+//    Calculation in Generational Distance and Inverted Generational
+//    Distance. EMO (2015), 110-125
+//  - Zitzler, E., Thiele, L., Laumanns, M., Fonseca, C.M., Grunert da Fonseca, V.:
+//    Performance Assessment of Multiobjective Optimizers: An Analysis and
+//    Review. IEEE Transactions on Evolutionary Computation 7(2) (2003), 117-132
+//  - Schott, J.R.: Fault Tolerant Design Using Single and Multicriteria
+//    Genetic Algorithm Optimization. Master's thesis, MIT (1995)
+//  - Zhou, A., Jin, Y., Zhang, Q., Sendhoff, B., Tsang, E.: Combining Model-based
+//    and Genetics-based Offspring Generation for Multi-objective Optimization
+//    Using a Convergence Criterion. IEEE CEC (2006), 892-899
+// ##############################################################
+
+// meanClosestDistance The mean over the points of from of the distance to their closest point of to
+func meanClosestDistance(from, to [][]float64, distance func(a, b []float64) float64) float64 {
+	var sum = 0.0
+	for _, a := range from {
+		var closest = math.Inf(1)
+		for _, b := range to {
+			closest = math.Min(closest, distance(a, b))
+		}
+		sum += closest
+	}
+	return sum / float64(len(from))
+}
+
+func euclidean(a, b []float64) float64 {
+	return floats.Distance(a, b, 2)
+}
+
+// dominanceDistance The distance from a reference point z to the part of the objective space
+// which a dominates: only the objectives where a is worse count.
+func dominanceDistance(z, a []float64) float64 {
+	var sum = 0.0
+	for k := range z {
+		var d = math.Max(a[k]-z[k], 0)
+		sum += d * d
+	}
+	return math.Sqrt(sum)
+}
+
+// generationalDistance GD: the mean distance of the points to the reference front (convergence, 0 is best)
+func generationalDistance(points, reference [][]float64) float64 {
+	return meanClosestDistance(points, reference, euclidean)
+}
+
+// invertedGenerationalDistance IGD: the mean distance of the reference front to the points
+// (convergence and coverage of the whole front, 0 is best)
+func invertedGenerationalDistance(points, reference [][]float64) float64 {
+	return meanClosestDistance(reference, points, euclidean)
+}
+
+// invertedGenerationalDistancePlus IGD+: as IGD, but measuring the distance from every reference
+// point to the region the points dominate. Unlike IGD, it never prefers a dominated set.
+func invertedGenerationalDistancePlus(points, reference [][]float64) float64 {
+	return meanClosestDistance(reference, points, dominanceDistance)
+}
+
+// additiveEpsilon The smallest value to subtract from all the objectives of the points so that
+// every reference point is weakly dominated (0 or less when the points dominate the reference front).
+func additiveEpsilon(points, reference [][]float64) float64 {
+	var epsilon = math.Inf(-1)
+	for _, z := range reference {
+		var best = math.Inf(1)
+		for _, a := range points {
+			var shift = math.Inf(-1)
+			for k := range z {
+				shift = math.Max(shift, a[k]-z[k])
+			}
+			best = math.Min(best, shift)
+		}
+		epsilon = math.Max(epsilon, best)
+	}
+	return epsilon
+}
+
+// multiplicativeEpsilon The smallest factor to divide all the objectives of the points by so that
+// every reference point is weakly dominated (1 or less when the points dominate the reference front).
+// All the objective values must be positive.
+func multiplicativeEpsilon(points, reference [][]float64) float64 {
+	var epsilon = math.Inf(-1)
+	for _, z := range reference {
+		var best = math.Inf(1)
+		for _, a := range points {
+			var factor = math.Inf(-1)
+			for k := range z {
+				factor = math.Max(factor, a[k]/z[k])
+			}
+			best = math.Min(best, factor)
+		}
+		epsilon = math.Max(epsilon, best)
+	}
+	return epsilon
+}
+
+// nearestNeighborDistances The distance from every point to its closest other point
+func nearestNeighborDistances(points [][]float64, distance func(a, b []float64) float64) []float64 {
+	var nearest = make([]float64, len(points))
+	for i, a := range points {
+		nearest[i] = math.Inf(1)
+		for j, b := range points {
+			if i != j {
+				nearest[i] = math.Min(nearest[i], distance(a, b))
+			}
+		}
+	}
+	return nearest
+}
+
+// spacing Schott's spacing: the standard deviation of the (Manhattan) distances of the points to
+// their nearest neighbour. 0 when the points are evenly spaced, whatever the front.
+func spacing(points [][]float64) float64 {
+	if len(points) < 2 {
+		return 0
+	}
+	var nearest = nearestNeighborDistances(points, func(a, b []float64) float64 { return floats.Distance(a, b, 1) })
+	var mean = floats.Sum(nearest) / float64(len(nearest))
+	var sum = 0.0
+	for _, d := range nearest {
+		sum += (d - mean) * (d - mean)
+	}
+	return math.Sqrt(sum / float64(len(nearest)-1))
+}
+
+// spread The generalized spread Δ of Deb's indicator: how far the points are from the extremes of
+// the reference front (the points with the worst value of each objective), and how uneven their
+// nearest neighbour distances are. 0 for an evenly spread set reaching the extremes.
+func spread(points, reference [][]float64) float64 {
+	var extremes = 0.0
+	for k := range reference[0] {
+		var extreme = reference[0]
+		for _, z := range reference {
+			if z[k] > extreme[k] {
+				extreme = z
+			}
+		}
+		extremes += meanClosestDistance([][]float64{extreme}, points, euclidean)
+	}
+	if len(points) < 2 {
+		return 1
+	}
+	var nearest = nearestNeighborDistances(points, euclidean)
+	var mean = floats.Sum(nearest) / float64(len(nearest))
+	var deviation = 0.0
+	for _, d := range nearest {
+		deviation += math.Abs(d - mean)
+	}
+	if extremes+float64(len(nearest))*mean == 0 {
+		return 0 // all the points and extremes are the same
+	}
+	return (extremes + deviation) / (extremes + float64(len(nearest))*mean)
+}
+
+// QualityReport The quality indicators of an approximation of the front of a benchmark problem
+type QualityReport struct {
+	name                  string
+	nPoints               int       // number of non-dominated points assessed
+	hypervolume           float64   // relative to reference, higher is better
+	reference             []float64 // the reference point of the hypervolume
+	referenceHypervolume  float64   // hypervolume of the samples of the true front, for comparison
+	generationalDistance  float64
+	igd, igdPlus          float64
+	additiveEpsilon       float64
+	multiplicativeEpsilon float64 // NaN if some objective values are not positive
+	spread, spacing       float64
+}
+
+// assessQuality Computes the quality indicators of the non-dominated points among the given
+// ones, relative to nSamples samples of the true front of the benchmark. The reference point
+// of the hypervolume is the worst point of the true front, plus 10% of its range.
+func assessQuality(points []Point, b *Benchmark, nSamples int) QualityReport {
+	var images = nonDominated(imagesOfPoints(points))
+	var front = b.front(nSamples)
+	var r = QualityReport{
+		name:                  b.name,
+		nPoints:               len(images),
+		reference:             referencePoint(front, 0.1),
+		multiplicativeEpsilon: math.NaN(),
+	}
+	r.hypervolume = hypervolume(images, r.reference)
+	r.referenceHypervolume = hypervolume(front, r.reference)
+	if len(images) == 0 {
+		r.generationalDistance, r.igd, r.igdPlus = math.Inf(1), math.Inf(1), math.Inf(1)
+		r.additiveEpsilon, r.spread, r.spacing = math.Inf(1), 1, 0
+		return r
+	}
+	r.generationalDistance = generationalDistance(images, front)
+	r.igd = invertedGenerationalDistance(images, front)
+	r.igdPlus = invertedGenerationalDistancePlus(images, front)
+	r.additiveEpsilon = additiveEpsilon(images, front)
+	if floats.Min(flatten(images)) > 0 && floats.Min(flatten(front)) > 0 {
+		r.multiplicativeEpsilon = multiplicativeEpsilon(images, front)
+	}
+	r.spread = spread(images, front)
+	r.spacing = spacing(images)
+	return r
+}
+
+// flatten All the values of a set of vectors, in one slice
+func flatten(vectors [][]float64) []float64 {
+	var all = []float64{}
+	for _, v := range vectors {
+		all = append(all, v...)
+	}
+	return all
+}
+
+func (r QualityReport) String() string {
+	var s = fmt.Sprintf("Quality of %d non-dominated points on %s:\n", r.nPoints, r.name)
+	s += fmt.Sprintf("  hypervolume:             %g (true front: %g, reference point %g)\n", r.hypervolume, r.referenceHypervolume, r.reference)
+	s += fmt.Sprintf("  generational distance:   %g\n", r.generationalDistance)
+	s += fmt.Sprintf("  IGD:                     %g\n", r.igd)
+	s += fmt.Sprintf("  IGD+:                    %g\n", r.igdPlus)
+	s += fmt.Sprintf("  additive epsilon:        %g\n", r.additiveEpsilon)
+	if !math.IsNaN(r.multiplicativeEpsilon) {
+		s += fmt.Sprintf("  multiplicative epsilon:  %g\n", r.multiplicativeEpsilon)
+	}
+	s += fmt.Sprintf("  spread:                  %g\n", r.spread)
+	s += fmt.Sprintf("  spacing:                 %g\n", r.spacing)
+	return s
+}
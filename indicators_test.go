@@ -0,0 +1,91 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestIndicators(t *testing.T) {
+	var front = [][]float64{{0, 2}, {1, 1}, {2, 0}}
+	var cases = []struct {
+		name   string
+		f      func(points, reference [][]float64) float64
+		points [][]float64
+		want   float64
+	}{
+		// (0.5, 2.5) is at √0.5 from (0, 2), and (2, 0) is on the front
+		{"GD", generationalDistance, [][]float64{{0.5, 2.5}, {2, 0}}, math.Sqrt(0.5) / 2},
+		{"GD on the front", generationalDistance, front, 0},
+		// (0, 2) is at √0.5 from (0.5, 2.5), (1, 1) at √2 from (2, 0), and (2, 0) at 0
+		{"IGD", invertedGenerationalDistance, [][]float64{{0.5, 2.5}, {2, 0}}, (math.Sqrt(0.5) + math.Sqrt(2)) / 3},
+		{"IGD of a part", invertedGenerationalDistance, [][]float64{{0, 2}}, (math.Sqrt(2) + math.Sqrt(8)) / 3},
+		// (0, 2) is at √0.5 from the region of (0.5, 2.5), (1, 1) at 1 from that of (2, 0)
+		{"IGD+", invertedGenerationalDistancePlus, [][]float64{{0.5, 2.5}, {2, 0}}, (math.Sqrt(0.5) + 1) / 3},
+		{"IGD+ of a dominating set", invertedGenerationalDistancePlus, [][]float64{{0, 0}}, 0},
+		// (1, 1) needs a shift of 1 to be dominated by (2, 0)
+		{"additive epsilon", additiveEpsilon, [][]float64{{0.5, 2.5}, {2, 0}}, 1},
+		{"additive epsilon on the front", additiveEpsilon, front, 0},
+		{"additive epsilon of a dominating set", additiveEpsilon, [][]float64{{-1, -1}}, -1},
+	}
+	for _, c := range cases {
+		if got := c.f(c.points, front); !closeTo(got, c.want, 1e-12) {
+			t.Errorf("%s: %g, want %g", c.name, got, c.want)
+		}
+	}
+}
+
+func TestMultiplicativeEpsilon(t *testing.T) {
+	var front = [][]float64{{1, 4}, {2, 2}, {4, 1}}
+	var cases = []struct {
+		points [][]float64
+		want   float64
+	}{
+		// every point of the front is dominated by the points divided by 2
+		{[][]float64{{2, 4}, {4, 2}}, 2},
+		{front, 1},
+		{[][]float64{{0.5, 0.5}}, 0.5},
+	}
+	for _, c := range cases {
+		if got := multiplicativeEpsilon(c.points, front); !closeTo(got, c.want, 1e-12) {
+			t.Errorf("multiplicative epsilon of %v: %g, want %g", c.points, got, c.want)
+		}
+	}
+}
+
+func TestSpacing(t *testing.T) {
+	var cases = []struct {
+		points [][]float64
+		want   float64
+	}{
+		{[][]float64{{0, 0}}, 0},
+		{[][]float64{{0, 2}, {1, 1}, {2, 0}}, 0},
+		// Manhattan distances 1, 1 and 2 to the nearest neighbour, of mean 4/3
+		{[][]float64{{0, 0}, {1, 0}, {3, 0}}, math.Sqrt((1./9 + 1./9 + 4./9) / 2)},
+		{[][]float64{{0, 0}, {1, 1}, {1, 2}}, math.Sqrt((1./9 + 1./9 + 4./9) / 2)},
+	}
+	for _, c := range cases {
+		if got := spacing(c.points); !closeTo(got, c.want, 1e-12) {
+			t.Errorf("spacing of %v: %g, want %g", c.points, got, c.want)
+		}
+	}
+}
+
+func TestSpread(t *testing.T) {
+	var front = [][]float64{{0, 2}, {1, 1}, {2, 0}}
+	var cases = []struct {
+		points [][]float64
+		want   float64
+	}{
+		{front, 0},
+		{[][]float64{{1, 1}}, 1},
+		// the extreme (2, 0) is at √2 from (1, 1), and both points have their neighbour at √2
+		{[][]float64{{0, 2}, {1, 1}}, 1. / 3},
+		// nearest neighbour distances √0.5, √0.5 and 3√0.5, of mean 5√0.5/3
+		{[][]float64{{0, 2}, {0.5, 1.5}, {2, 0}}, 8. / 15},
+	}
+	for _, c := range cases {
+		if got := spread(c.points, front); !closeTo(got, c.want, 1e-12) {
+			t.Errorf("spread of %v: %g, want %g", c.points, got, c.want)
+		}
+	}
+}
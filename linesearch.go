@@ -0,0 +1,183 @@
+package main
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+// ##############################################################
+// Line searches for vector-valued functions: choose the step t along a
+// direction d so that the objectives decrease enough at P(x + t d),
+// P being the projection on the box constraints (if any).
+// ##############################################################
+
+// LineSearcher : A strategy to choose the step length along a descent direction.
+// objectives are the indices of the objectives which must decrease (nil for all of them).
+// search returns the accepted point, or false if no acceptable step was found.
+type LineSearcher interface {
+	search(current *Point, direction []float64, initialStep float64, objectives []int) (Point, bool)
+	evaluations() int // number of evaluations of the problem (objectives and Jacobian) so far
+}
+
+// lineSearching : The optimizers which can use a LineSearcher
+type lineSearching interface {
+	getLineSearcher() LineSearcher
+}
+
+// searchedObjectives The indices of the objectives to consider, all of them if nil
+func searchedObjectives(pt *Point, objectives []int) []int {
+	if objectives != nil {
+		return objectives
+	}
+	var all = make([]int, pt.Problem.nDims)
+	for k := range all {
+		all[k] = k
+	}
+	return all
+}
+
+// trialPoint Evaluates P(x + t d)
+func trialPoint(current *Point, direction []float64, t float64) Point {
+	var x = floats.AddScaledTo(make([]float64, len(direction)), current.inputs, t, direction)
+	return current.Problem.evaluate(current.Problem.project(x))
+}
+
+// sufficientDecrease The multiobjective Armijo condition, with the actual (projected) displacement s:
+// f_k(x + s) <= f_k(x) + c1 grad f_k(x)^T s for all the objectives k.
+// The trial is also rejected if the objectives are not differentiable there, or if the step
+// is so short that the point does not move.
+func sufficientDecrease(current, trial *Point, c1 float64, objectives []int) bool {
+	return decreaseFrom(imagesOf(current), current, trial, c1, objectives)
+}
+
+// decreaseFrom The Armijo condition relative to reference values of the objectives instead of
+// their values at the current point: f_k(x + s) <= reference_k + c1 grad f_k(x)^T s.
+func decreaseFrom(reference []float64, current, trial *Point, c1 float64, objectives []int) bool {
+	if !isFinite(trial.gradient.Data().([]float64)) || floats.Equal(trial.inputs, current.inputs) {
+		return false
+	}
+	var s = floats.SubTo(make([]float64, len(trial.inputs)), trial.inputs, current.inputs)
+	var grads = gradientsOf(current)
+	var after = imagesOf(trial)
+	for _, k := range objectives {
+		if !(after[k] <= reference[k]+c1*floats.Dot(grads[k], s)) {
+			return false
+		}
+	}
+	return true
+}
+
+// maxSlope The largest directional derivative max_k grad f_k(x)^T d
+func maxSlope(pt *Point, direction []float64, objectives []int) float64 {
+	var grads = gradientsOf(pt)
+	var slope = math.Inf(-1)
+	for _, k := range objectives {
+		slope = math.Max(slope, floats.Dot(grads[k], direction))
+	}
+	return slope
+}
+
+// isFinite Tells if all the values are finite numbers
+func isFinite(values []float64) bool {
+	for _, v := range values {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// ArmijoBacktracking : Multiobjective Armijo rule (Fliege & Svaiter, 2000). The step is
+// multiplied by contraction until all the objectives decrease enough.
+type ArmijoBacktracking struct {
+	c1          float64 // fraction of the predicted decrease which must be achieved
+	contraction float64 // factor applied to the step after a rejected trial
+	maxTrials   int     // number of trials before giving up
+	count       int     // evaluations so far
+}
+
+// newArmijoBacktracking Creates an Armijo line search with the usual parameters
+func newArmijoBacktracking() *ArmijoBacktracking {
+	return &ArmijoBacktracking{c1: 1e-4, contraction: 0.5, maxTrials: 50}
+}
+
+func (ls *ArmijoBacktracking) search(current *Point, direction []float64, initialStep float64, objectives []int) (Point, bool) {
+	objectives = searchedObjectives(current, objectives)
+	var t = initialStep
+	for trial := 0; trial < ls.maxTrials; trial++ {
+		var pt = trialPoint(current, direction, t)
+		ls.count++
+		if sufficientDecrease(current, &pt, ls.c1, objectives) {
+			return pt, true
+		}
+		t *= ls.contraction
+	}
+	return *current, false
+}
+
+func (ls *ArmijoBacktracking) evaluations() int {
+	return ls.count
+}
+
+// WolfeLineSearch : Wolfe conditions for vector optimization (Lucambio Pérez & Prudente, 2018).
+// Besides the Armijo condition, the step must reduce the steepest slope max_k grad f_k^T d:
+//
+//	weak:   max_k grad f_k(x + t d)^T d >= c2 max_k grad f_k(x)^T d
+//	strong: |max_k grad f_k(x + t d)^T d| <= -c2 max_k grad f_k(x)^T d
+//
+// so that the steps are not uselessly short. The step is found by bracketing and bisection.
+// The curvature condition ignores the box constraints: prefer ArmijoBacktracking on bounded problems.
+type WolfeLineSearch struct {
+	c1        float64 // sufficient decrease parameter
+	c2        float64 // curvature parameter, c1 < c2 < 1
+	strong    bool    // use the strong curvature condition
+	maxTrials int     // number of trials before giving up
+	count     int     // evaluations so far
+}
+
+// newWolfeLineSearch Creates a weak or strong Wolfe line search with the usual parameters
+func newWolfeLineSearch(strong bool) *WolfeLineSearch {
+	return &WolfeLineSearch{c1: 1e-4, c2: 0.9, strong: strong, maxTrials: 50}
+}
+
+func (ls *WolfeLineSearch) search(current *Point, direction []float64, initialStep float64, objectives []int) (Point, bool) {
+	objectives = searchedObjectives(current, objectives)
+	var slope0 = maxSlope(current, direction, objectives)
+	if slope0 >= 0 {
+		return *current, false // not a descent direction
+	}
+
+	// [lo, hi] brackets the acceptable steps
+	var lo, hi = 0.0, math.Inf(1)
+	var t = initialStep
+	var best, found = *current, false // last point satisfying the Armijo condition
+	for trial := 0; trial < ls.maxTrials; trial++ {
+		var pt = trialPoint(current, direction, t)
+		ls.count++
+		if !sufficientDecrease(current, &pt, ls.c1, objectives) {
+			hi = t
+		} else {
+			best, found = pt, true
+			var slope = maxSlope(&pt, direction, objectives)
+			switch {
+			case ls.strong && slope > -ls.c2*slope0:
+				hi = t // went past a minimum of an objective
+			case slope < ls.c2*slope0:
+				lo = t // still steeply descending, go further
+			default:
+				return pt, true
+			}
+		}
+		if math.IsInf(hi, 1) {
+			t = 2 * lo
+		} else {
+			t = 0.5 * (lo + hi)
+		}
+	}
+	return best, found // no Wolfe step, but the objectives decreased
+}
+
+func (ls *WolfeLineSearch) evaluations() int {
+	return ls.count
+}
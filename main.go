@@ -1,7 +1,9 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"log"
 	"os"
 
 	// "strings"
@@ -10,10 +12,12 @@ import (
 	"encoding/csv"
 
 	"github.com/Arafatk/glot"
+	"gonum.org/v1/gonum/optimize"
 	"gorgonia.org/tensor"
 )
 
-func run(o Optimizer, optiIndex int) []Point {
+// descend Iterates an optimizer until it converges, and returns its trajectory.
+func descend(o Optimizer) []Point {
 	// Main optimization loop
 	var trajectory = []Point{}
 	trajectory = append(trajectory, *o.getCurrent()) // Set the first point as the begining of the trajectory
@@ -21,7 +25,15 @@ func run(o Optimizer, optiIndex int) []Point {
 		next := o.move(o.getCurrent())
 		trajectory = append(trajectory, next)
 	}
+	return trajectory
+}
+
+func run(o Optimizer, optiIndex int) []Point {
+	var trajectory = descend(o)
 	fmt.Printf("Converged in %d iterations.\n", len(trajectory)-1)
+	if o, ok := o.(lineSearching); ok && o.getLineSearcher() != nil {
+		fmt.Printf("The line search used %d evaluations of the problem.\n", o.getLineSearcher().evaluations())
+	}
 	fmt.Printf("Minimum found at: %.2f\n", o.getCurrent().inputs)
 	fmt.Println()
 	fmt.Println("Exiting...")
@@ -29,50 +41,237 @@ func run(o Optimizer, optiIndex int) []Point {
 	return trajectory
 }
 
+// problemPath Optional problem definition file (see problem_file.go), or name of a gonum test
+// function (see gonumCatalogue), replacing BealeProblem
+var problemPath = flag.String("problem", "", "load the problem to solve from this JSON or YAML file, or solve this gonum test function (Rosenbrock, Wood, ...)")
+
+// checkDerivatives Compare the derivatives of the problem with numerical ones before solving it (see numdiff.go)
+var checkDerivatives = flag.Bool("check-derivatives", false, "compare the derivatives of the problem with finite differences at the starting point")
+
+// nsga2 Run NSGA-II on the ZDT problems instead of solving the problem (see evolveZDTFronts)
+var nsga2 = flag.Bool("nsga2", false, "run NSGA-II on the ZDT problems, and write the fronts found in nsga2_ZDT*_front.csv")
+
 func main() {
+	flag.Parse()
+	if *nsga2 {
+		evolveZDTFronts()
+		return
+	}
 	// solveMonoObjectiveProblem()
+	// solveManyObjectiveProblem()
+	// sampleParetoFront()
+	// compareDescentMethods()
+	// sweepFronts()
 	solveMultiobjectiveProblem()
 
 }
 
 func solveMultiobjectiveProblem() {
 	p = BealeProblem
+	var startingPoint = []float64{1.0, 4.0} // Coordinates in the space of variables
+	if catalogued, start, ok := gonumProblem(*problemPath); ok {
+		p, startingPoint = catalogued, start
+	} else if *problemPath != "" {
+		loaded, starts, err := loadProblemFile(*problemPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		p = loaded
+		startingPoint = make([]float64, p.nVars) // the origin, projected in the bounds, unless the file suggests better
+		if len(starts) > 0 {
+			startingPoint = starts[0]
+		}
+	}
 	var nVars, nDims int = p.getDims() // The problem is defined in the file problem.go
 	fmt.Println("Welcome to the IBISC superoptimizer. Time to superoptimize your life.")
 	fmt.Printf("Starting with an optimization problem: %d cost functions to minimize, depending on %d variables.\n", nDims, nVars)
 
-	var startingPoint = []float64{1.0, 4.0} // Coordinates in the space of variables
-	var first = p.evaluate(startingPoint)
+	if *checkDerivatives {
+		fmt.Print(CheckDerivatives(&p, startingPoint))
+	}
+	first, err := p.startingPoint(startingPoint, true) // project it in the box if it is outside
+	if err != nil {
+		panic(err)
+	}
 
-	// Create some Optimizers. Pick your favorite algorithm, see optimizers.go for the list.
+	// Create some Optimizers from the starting point. Pick your favorite algorithm, see optimizers.go for the list.
 	var stopTolerance float64 = 0.000001
-	var myOptis = []Optimizer{
-		&MonoGradientDescent{&first, 0, stopTolerance, 10000, 0.01},
-		// &MonoGradientDescent{&first, 1, stopTolerance, 10000, 0.01},
-		// &SteepestDescent{&first, stopTolerance, 10000, false, 1.0},
+	var myOptis = []func(start *Point) Optimizer{
+		func(s *Point) Optimizer { return &MonoGradientDescent{s, 0, stopTolerance, 10000, false, 0.01, nil} },
+		// func(s *Point) Optimizer { return &MonoGradientDescent{s, 0, stopTolerance, 10000, false, 1.0, newArmijoBacktracking()} },
+		// func(s *Point) Optimizer { return &MonoGradientDescent{s, 0, stopTolerance, 10000, false, 1.0, newBarzilaiBorwein(false)} },
+		// func(s *Point) Optimizer { return &MonoGradientDescent{s, 1, stopTolerance, 10000, false, 0.01, nil} },
+		// func(s *Point) Optimizer { return newMomentumDescent(s, 0, stopTolerance, 10000, 0.01, 0.9, true) },
+		// func(s *Point) Optimizer { return newAdaGrad(s, 0, stopTolerance, 10000, 0.5) },
+		// func(s *Point) Optimizer { return newRMSProp(s, 0, stopTolerance, 10000, 0.01) },
+		// func(s *Point) Optimizer { return newAdam(s, 0, stopTolerance, 10000, 0.05) },
+		// func(s *Point) Optimizer { return &SteepestDescent{s, stopTolerance, 10000, false, 1.0, nil} },
+		// func(s *Point) Optimizer { return &SteepestDescent{s, stopTolerance, 10000, false, 1.0, newWolfeLineSearch(true)} },
+		// func(s *Point) Optimizer { return &SteepestDescent{s, stopTolerance, 10000, false, 1.0, newBarzilaiBorwein(false)} },
+		// func(s *Point) Optimizer { return &SteepestDescent{s, stopTolerance, 10000, false, 1.0, newLipschitzBacktracking()} },
+		// func(s *Point) Optimizer { return newMultiobjectiveNewton(s, stopTolerance, 100) },
+		// func(s *Point) Optimizer { return newMultiobjectiveBFGS(s, stopTolerance, 1000) },
+		// func(s *Point) Optimizer { return newLimitedMemoryBFGS(s, stopTolerance, 1000, 10) },
 	}
 	// var names = []string{"Gradient Descent on Function 1", "Gradient Descent on Function 2", "SteepestDescent"}
 	var names = []string{"SteepestDescent"}
 
-	// Prepare a plot
-	persist := true // Keep the Gnuplot window open
-	debug := false  // do not print commands to stdout
-	plot3d, _ := glot.NewPlot(3, persist, debug)
-	plot2d, _ := glot.NewPlot(2, persist, debug)
-
 	fmt.Println("Starting optimization...")
 	var trajectories [][]Point = make([][]Point, len(myOptis))
 
 	for a := 0; a < len(myOptis); a++ {
-		trajectories[a] = run(myOptis[a], a)
+		var o = myOptis[a](&first)
+		if p.isConstrained() {
+			// the optimizers only stay in the bounds, the other constraints need the augmented Lagrangian method
+			o = newAugmentedLagrangian(&first, myOptis[a], stopTolerance, 100)
+		}
+		trajectories[a] = run(o, a)
+	}
+
+	// The plots show the functions of x and y
+	if nVars != 2 {
+		fmt.Printf("The trajectories are written in trajectory*.csv, but only the problems of 2 variables are plotted.\n")
+		return
 	}
 
+	// Prepare a plot
+	persist := true // Keep the Gnuplot window open
+	debug := false  // do not print commands to stdout
+	plot3d, _ := glot.NewPlot(3, persist, debug)
+	plot2d, _ := glot.NewPlot(2, persist, debug)
+
 	plot3dTrajectories(plot3d, &names)
 	plot2dTrajectories(plot2d, &names)
 
 	time.Sleep(time.Second * 2)
 }
 
+// sampleParetoFront Approximates the Pareto front of the problem with steepest descents
+// from 50 starting points, and writes it with all the trajectories in multistart_*.csv.
+func sampleParetoFront() {
+	if err := checkFiniteBounds(&p); err != nil {
+		log.Fatal(err)
+	}
+	var starts = haltonPoints(&p, 50)
+	var steepest = func(start *Point) Optimizer {
+		return &SteepestDescent{start, 0.000001, 1000, false, 1.0, newArmijoBacktracking()}
+	}
+	front, trajectories := multiStart(&p, starts, steepest, 0)
+	fmt.Printf("Hypervolume of the front: %g\n", pointsHypervolume(front, referencePoint(imagesOfPoints(front), 0.1)))
+	if err := multiStartToCSV(front, trajectories, "multistart"); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// sweepFronts Approximates the Pareto front of the problem with a weighted-sum sweep, the normal
+// boundary intersection and the Pascoletti-Serafini scalarization solved by gradient descents, and
+// an epsilon-constraint sweep solved by gonum's BFGS, and writes them in *_front.csv.
+func sweepFronts() {
+	var start = make([]float64, p.nVars)
+	var descent = func(start *Point) Optimizer {
+		return &MonoGradientDescent{start, 0, 0.000001, 1000, false, 1.0, newArmijoBacktracking()}
+	}
+	var bfgs = func(start *Point) Optimizer {
+		return newGonumOptimizer(start, &optimize.BFGS{}, nil)
+	}
+	var fronts = map[string][]Point{
+		"weightedsum": weightedSumSweep(&p, start, 20, descent),
+		"nbi":         normalBoundaryIntersection(&p, start, 20, descent),
+		"pascoletti":  pascolettiSerafini(&p, start, 20, descent),
+		"epsilon":     epsilonConstraintSweep(&p, start, 0, 20, bfgs),
+	}
+	for name, front := range fronts {
+		fmt.Printf("%s: hypervolume %g\n", name, pointsHypervolume(front, referencePoint(imagesOfPoints(front), 0.1)))
+		if err := pointsToCSV(front, name+"_front.csv"); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// compareDescentMethods Runs descent methods from the same 30 starting points on ZDT1,
+// and prints the quality indicators of the fronts they find.
+func compareDescentMethods() {
+	var b = newZDT(1, 10)
+	var starts = haltonPoints(&b.Problem, 30)
+	var methods = []struct {
+		name         string
+		newOptimizer func(start *Point) Optimizer
+	}{
+		{"MonoGradientDescent on f1", func(start *Point) Optimizer {
+			return &MonoGradientDescent{start, 0, 0.000001, 1000, false, 1.0, newArmijoBacktracking()}
+		}},
+		{"SteepestDescent", func(start *Point) Optimizer {
+			return &SteepestDescent{start, 0.000001, 1000, false, 1.0, newArmijoBacktracking()}
+		}},
+		{"SteepestDescent with Barzilai-Borwein steps", func(start *Point) Optimizer {
+			return &SteepestDescent{start, 0.000001, 1000, false, 1.0, newBarzilaiBorwein(false)}
+		}},
+		{"SteepestDescent with Lipschitz steps", func(start *Point) Optimizer {
+			return &SteepestDescent{start, 0.000001, 1000, false, 1.0, newLipschitzBacktracking()}
+		}},
+		{"MultiobjectiveNewton", func(start *Point) Optimizer {
+			return newMultiobjectiveNewton(start, 0.000001, 100)
+		}},
+		{"MultiobjectiveBFGS", func(start *Point) Optimizer {
+			return newMultiobjectiveBFGS(start, 0.000001, 1000)
+		}},
+		{"LimitedMemoryBFGS", func(start *Point) Optimizer {
+			return newLimitedMemoryBFGS(start, 0.000001, 1000, 10)
+		}},
+	}
+	for _, m := range methods {
+		fmt.Printf("%s:\n", m.name)
+		front, _ := multiStart(&b.Problem, starts, m.newOptimizer, 0)
+		fmt.Println(assessQuality(front, &b, 200))
+	}
+}
+
+// solveManyObjectiveProblem Runs NSGA-III on DTLZ problems with 3 to 10 objectives, and measures
+// how far its final population is from the true Pareto front.
+func solveManyObjectiveProblem() {
+	// Reference directions of Deb & Jain (2014), and their numbers of generations for DTLZ2
+	var settings = []struct {
+		nObjectives, outer, inner int
+		generations               uint
+	}{
+		{3, 12, 0, 250},
+		{5, 6, 0, 350},
+		{8, 3, 2, 500},
+		{10, 3, 2, 750},
+	}
+	for _, number := range []int{1, 2} {
+		for _, s := range settings {
+			var b = newDTLZ(number, s.nObjectives, 0)
+			var references = referenceDirections(s.nObjectives, s.outer, s.inner)
+			o, err := newNSGA3(&b.Problem, references, s.generations, 1)
+			if err != nil {
+				log.Fatal(err)
+			}
+			fmt.Printf("%s with %d objectives, %d reference directions:\n", b.name, s.nObjectives, len(references))
+			front, _ := runPopulation(o)
+			fmt.Printf("Generational distance to the samples of the Pareto front: %g\n\n", generationalDistance(imagesOfPoints(front), b.front(10*len(references))))
+		}
+	}
+}
+
+// evolveZDTFronts Runs NSGA-II on the ZDT problems with the settings of Deb et al. (2002),
+// prints the quality indicators of the fronts found, and writes them in nsga2_ZDT*_front.csv.
+func evolveZDTFronts() {
+	var problems = []struct{ number, nVars int }{{1, 30}, {2, 30}, {3, 30}, {4, 10}, {6, 10}}
+	for _, z := range problems {
+		var b = newZDT(z.number, z.nVars)
+		o, err := newNSGA2(&b.Problem, 100, 250, 1)
+		if err != nil {
+			log.Fatal(err)
+		}
+		front, _ := runPopulation(o)
+		fmt.Print(assessQuality(front, &b, 500))
+		if err := pointsToCSV(front, fmt.Sprintf("nsga2_%s_front.csv", b.name)); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
 func tryGnuplotCmd(plot *glot.Plot, cmd string) {
 	err := plot.Cmd(cmd)
 	if err != nil {
@@ -82,16 +281,17 @@ func tryGnuplotCmd(plot *glot.Plot, cmd string) {
 
 func trajToCSV(traj *[]Point, optiIndex int) {
 	// Create a CSV file containing our data-points
-	// First columns are the variables, the the objective values
+	// First columns are the variables, then the objective values
 	var data = [][]string{}
 	for l := 0; l < len(*traj); l++ {
-		thisPoint := make([]string, 2+p.nDims)
-		thisPoint[0] = fmt.Sprintf("%.2f", (*traj)[l].inputs[0])
-		thisPoint[1] = fmt.Sprintf("%.2f", (*traj)[l].inputs[1])
+		thisPoint := make([]string, p.nVars+p.nDims)
+		for i, xi := range (*traj)[l].inputs {
+			thisPoint[i] = fmt.Sprintf("%.2f", xi)
+		}
 		im := (*traj)[l].images
 		for j := 0; j < p.nDims; j++ {
 			val, _ := im.At(j, 0)
-			thisPoint[2+j] = fmt.Sprintf("%.2f", val)
+			thisPoint[p.nVars+j] = fmt.Sprintf("%.2f", val)
 		}
 		data = append(data, thisPoint)
 	}
@@ -114,12 +314,12 @@ func plot3dTrajectories(plot *glot.Plot, names *[]string) {
 	// Prepare the plot
 	var cmd string = "set style data linespoints; load 'persalpalette.pal'; "
 	cmd += "set xyplane at 0; set xlabel 'x';  set ylabel 'y'; "
-	cmd += "set xrange [-5:5]; set yrange [-5:5]; "
+	cmd += fmt.Sprintf("set xrange %s; set yrange %s; ", p.plotRange(0), p.plotRange(1))
 	cmd += "set isosample 20; set contour surface; set cntrparam levels 30; unset clabel; "
 
 	// Plot the functions
 	cmd += "splot "
-	for j, eq := range *p.equations {
+	for j, eq := range p.getEquations() {
 		cmd += eq + fmt.Sprintf(" ls %d", j+1) + " title '" + eq + "', "
 	}
 
@@ -146,20 +346,20 @@ func plot3dTrajectories(plot *glot.Plot, names *[]string) {
 func plot2dTrajectories(plot *glot.Plot, names *[]string) {
 
 	// Prepare the plot
-	var cmd string = "set xrange [-5:5]; set yrange [-5:5]; set isosample 100; "
+	var cmd string = fmt.Sprintf("set xrange %s; set yrange %s; set isosample 100; ", p.plotRange(0), p.plotRange(1))
 
 	// Save the contours to dat files
 	cmd += "set contour base; set cntrparam levels 50; unset surface; "
-	for j, eq := range *p.equations {
+	for j, eq := range p.getEquations() {
 		cmd += fmt.Sprintf("set table 'Function%d.dat'; splot ", j+1) + eq + fmt.Sprintf(" title \"Function %d\"", j+1) + "; unset table; "
 	}
 
 	// Prepare the final plot
-	cmd += "reset; set xrange [-5:5]; set yrange [-5:5]; unset clabel; "
+	cmd += fmt.Sprintf("reset; set xrange %s; set yrange %s; unset clabel; ", p.plotRange(0), p.plotRange(1))
 	cmd += "set xlabel 'x';  set ylabel 'y'; set key below; load 'persalpalette.pal'; plot "
 
 	// Plot the contours
-	for j, eq := range *p.equations {
+	for j, eq := range p.getEquations() {
 		cmd += fmt.Sprintf("'Function%d.dat'", j+1) + fmt.Sprintf(" with lines ls %d title '", j+1) + eq + "', "
 	}
 
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+// ##############################################################
+// MOEA/D
+//
+// Reference: Zhang, Q., Li, H.: MOEA/D: A Multiobjective Evolutionary
+// Algorithm Based on Decomposition. IEEE Transactions on Evolutionary
+// Computation 11(6) (2007), 712-731
+// ##############################################################
+
+// Aggregation The scalarizing function of the subproblems of MOEA/D (see scalarizations.go)
+type Aggregation uint8
+
+// The available aggregations
+const (
+	TchebycheffAggregation Aggregation = iota // max_k w_k |f_k - z_k|
+	PBIAggregation                            // penalty-based boundary intersection, theta = 5
+)
+
+// MOEAD : The multiobjective evolutionary algorithm based on decomposition. The problem is split
+// into scalar subproblems, one per weight vector, each with its current best point. Every
+// generation, each subproblem breeds a child from parents taken among the subproblems with
+// the closest weights, and the child replaces the points of the neighbouring subproblems it
+// improves. The constraints are handled by comparing the violations first.
+type MOEAD struct {
+	problem          *Problem
+	population       []Point               // the best point of each subproblem
+	weights          [][]float64           // weight vectors of the subproblems
+	neighbors        [][]int               // the closest subproblems of each one (itself included)
+	ideal            []float64             // best value found for each objective
+	subproblems      []scalarizingFunction // the aggregation of each subproblem
+	maxGenerations   uint                  // number of generations before halt
+	neighborhoodRate float64               // probability to pick the parents in the neighborhood rather than everywhere
+	maxReplacements  int                   // max number of subproblems a child can take over
+	etaCrossover     float64               // distribution index of the SBX crossover
+	mutationRate     float64               // probability to mutate every variable
+	etaMutation      float64               // distribution index of the polynomial mutation
+	localSearchRate  float64               // probability to improve a child with a gradient step (0 to disable)
+	rng              *rand.Rand            // seeded, for reproducible runs
+}
+
+// newMOEAD Creates a MOEAD optimizer with about nWeights uniformly spread weight vectors
+// (the Das and Dennis lattice), neighborhoods of the given size, a random initial population
+// in the box of the problem, and the usual parameters. Each child is improved by a gradient step
+// with probability localSearchRate (0 to disable the local search).
+func newMOEAD(p *Problem, nWeights, neighborhood int, aggregation Aggregation, maxGenerations uint, localSearchRate float64, seed int64) (*MOEAD, error) {
+	if err := checkFiniteBounds(p); err != nil {
+		return nil, err
+	}
+	var weights = simplexLattice(p.nDims, latticeDivisions(p.nDims, nWeights))
+	if neighborhood < 2 || neighborhood > len(weights) {
+		return nil, fmt.Errorf("neighborhood of %d subproblems, out of %d", neighborhood, len(weights))
+	}
+	if localSearchRate < 0 || localSearchRate > 1 {
+		return nil, fmt.Errorf("local search rate %g, out of [0, 1]", localSearchRate)
+	}
+	var o = &MOEAD{
+		problem:          p,
+		weights:          weights,
+		neighbors:        make([][]int, len(weights)),
+		ideal:            make([]float64, p.nDims),
+		subproblems:      make([]scalarizingFunction, len(weights)),
+		maxGenerations:   maxGenerations,
+		neighborhoodRate: 0.9,
+		maxReplacements:  2,
+		etaCrossover:     20,
+		mutationRate:     1 / float64(p.nVars),
+		etaMutation:      20,
+		localSearchRate:  localSearchRate,
+		rng:              rand.New(rand.NewSource(seed)),
+	}
+
+	for i, w := range weights {
+		// the neighbors are the subproblems with the closest weights
+		var order = make([]int, len(weights))
+		for j := range order {
+			order[j] = j
+		}
+		sort.SliceStable(order, func(a, b int) bool {
+			return floats.Distance(w, weights[order[a]], 2) < floats.Distance(w, weights[order[b]], 2)
+		})
+		o.neighbors[i] = order[:neighborhood]
+
+		switch aggregation {
+		case TchebycheffAggregation:
+			// null weights would ignore an objective, and give weakly optimal points
+			var positive = make([]float64, len(w))
+			for k := range w {
+				positive[k] = math.Max(w[k], 1e-6)
+			}
+			o.subproblems[i] = augmentedTchebycheffFunction(positive, o.ideal, 0)
+		case PBIAggregation:
+			o.subproblems[i] = penaltyBoundaryIntersectionFunction(w, o.ideal, 5)
+		}
+	}
+
+	for k := range o.ideal {
+		o.ideal[k] = math.Inf(1)
+	}
+	o.population = make([]Point, len(weights))
+	for i := range o.population {
+		o.population[i] = p.evaluateObjectives(randomInBox(p, o.rng))
+		o.updateIdeal(&o.population[i])
+	}
+	return o, nil
+}
+
+// updateIdeal Lowers the ideal point with the objective values of a point.
+// The subproblems use the ideal slice, so they follow its updates.
+func (o *MOEAD) updateIdeal(pt *Point) {
+	for k, fk := range imagesOf(pt) {
+		o.ideal[k] = math.Min(o.ideal[k], fk)
+	}
+}
+
+// better Tells if a is better than b for the subproblem i: lower constraint violation,
+// then lower aggregated value.
+func (o *MOEAD) better(i int, a, b *Point) bool {
+	if a.violation != b.violation {
+		return a.violation < b.violation
+	}
+	var va, _ = o.subproblems[i](imagesOf(a))
+	var vb, _ = o.subproblems[i](imagesOf(b))
+	return va < vb
+}
+
+// localSearch Improves a point on the subproblem i with one projected gradient step on the
+// aggregated function, using the analytic Jacobian of the problem, with Armijo backtracking.
+func (o *MOEAD) localSearch(i int, pt Point) Point {
+	var start = o.problem.evaluate(pt.inputs)
+	var value, c = o.subproblems[i](imagesOf(&start))
+	var grad = make([]float64, o.problem.nVars)
+	for k, g := range gradientsOf(&start) {
+		floats.AddScaled(grad, c[k], g)
+	}
+	if !isFinite(grad) {
+		return pt
+	}
+	for t := 1.0; t > 1e-10; t /= 2 {
+		var x = o.problem.project(floats.AddScaledTo(make([]float64, len(grad)), start.inputs, -t, grad))
+		var trial = o.problem.evaluateObjectives(x)
+		var trialValue, _ = o.subproblems[i](imagesOf(&trial))
+		var s = floats.SubTo(make([]float64, len(x)), x, start.inputs)
+		if trial.violation <= start.violation && trialValue <= value+1e-4*floats.Dot(grad, s) && floats.Norm(s, 2) > 0 {
+			return trial
+		}
+	}
+	return pt
+}
+
+func (o *MOEAD) evolve() []Point {
+	for _, i := range o.rng.Perm(len(o.population)) {
+		// Mating pool: the neighborhood, or the whole population
+		var pool = o.neighbors[i]
+		if o.rng.Float64() >= o.neighborhoodRate {
+			pool = o.rng.Perm(len(o.population))
+		}
+		var parent1 = o.population[pool[o.rng.Intn(len(pool))]].inputs
+		var parent2 = o.population[pool[o.rng.Intn(len(pool))]].inputs
+		var child, _ = sbxCrossover(o.problem, parent1, parent2, o.etaCrossover, o.rng)
+		polynomialMutation(o.problem, child, o.mutationRate, o.etaMutation, o.rng)
+		var pt = o.problem.evaluateObjectives(child)
+		if o.rng.Float64() < o.localSearchRate {
+			pt = o.localSearch(i, pt)
+		}
+		o.updateIdeal(&pt)
+
+		// Neighborhood replacement
+		var replaced = 0
+		for _, j := range o.rng.Perm(len(pool)) {
+			if replaced >= o.maxReplacements {
+				break
+			}
+			if o.better(pool[j], &pt, &o.population[pool[j]]) {
+				o.population[pool[j]] = pt
+				replaced++
+			}
+		}
+	}
+	return append([]Point{}, o.population...)
+}
+
+func (o *MOEAD) getPopulation() []Point {
+	return append([]Point{}, o.population...)
+}
+
+func (o *MOEAD) checkConverged(generation uint) bool {
+	if generation >= o.maxGenerations {
+		fmt.Printf("Stopping after %d generations.\n", o.maxGenerations)
+		return true
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+package main
+
+import "testing"
+
+// TestMOEADOnZDT1 Runs MOEA/D on ZDT1 with both aggregations, with and without the gradient
+// local search, and measures the IGD to samples of the true front. After only 60 generations,
+// the local search must already have brought the population close to the front.
+func TestMOEADOnZDT1(t *testing.T) {
+	var cases = []struct {
+		aggregation     Aggregation
+		localSearchRate float64
+		generations     uint
+		maxIGD          float64
+	}{
+		{TchebycheffAggregation, 0, 100, 1},
+		{TchebycheffAggregation, 0.2, 100, 1},
+		{PBIAggregation, 0, 100, 1},
+		{PBIAggregation, 0.2, 100, 1},
+	}
+	var b = newZDT(1, 10)
+	var front = b.front(500)
+	for _, c := range cases {
+		o, err := newMOEAD(&b.Problem, 100, 20, c.aggregation, c.generations, c.localSearchRate, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		population, _ := runPopulation(o)
+		if igd := invertedGenerationalDistance(imagesOfPoints(population), front); !(igd < c.maxIGD) {
+			t.Errorf("aggregation %d, local search rate %g: IGD %g, want less than %g", c.aggregation, c.localSearchRate, igd, c.maxIGD)
+		}
+	}
+}
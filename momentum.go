@@ -0,0 +1,169 @@
+package main
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+// ##############################################################
+// Accelerated and adaptive variants of MonoGradientDescent. They embed it,
+// to share its state, its trajectory and its convergence checks, and only
+// replace the move: stepLength is their learning rate, and they always
+// move by their rule (no line search), staying in the box of the problem.
+//
+// References:
+//  - Polyak, B.T.: Some methods of speeding up the convergence of iteration
+//    methods. USSR Computational Mathematics and Mathematical Physics 4(5) (1964), 1-17
+//  - Sutskever, I., Martens, J., Dahl, G., Hinton, G.: On the importance of
+//    initialization and momentum in deep learning. ICML (2013), 1139-1147
+//  - Duchi, J., Hazan, E., Singer, Y.: Adaptive Subgradient Methods for Online
+//    Learning and Stochastic Optimization. JMLR 12 (2011), 2121-2159
+//  - Tieleman, T., Hinton, G.: Lecture 6.5, RMSProp. COURSERA: Neural Networks
+//    for Machine Learning (2012)
+//  - Kingma, D.P., Ba, J.: Adam: A Method for Stochastic Optimization. ICLR (2015)
+// ##############################################################
+
+// gradientOf A copy of the gradient of one objective at a point
+func gradientOf(pt *Point, function int) []float64 {
+	return append([]float64{}, gradientsOf(pt)[function]...)
+}
+
+// moveTo Evaluates the projection of x in the box, and makes it the current point
+func (o *MonoGradientDescent) moveTo(x []float64) Point {
+	var pt = o.current.Problem.evaluate(o.current.Problem.project(x))
+	o.current = &pt
+	return pt
+}
+
+// MomentumDescent : Gradient descent with heavy-ball momentum: the velocity accumulates the
+// previous steps, v = momentum v - stepLength grad f(x), and x moves by v. With Nesterov's
+// acceleration, the gradient is taken at the look-ahead point x + momentum v instead.
+// In a box, the velocity is the step actually taken once projected, so that it does not keep
+// pushing against a bound.
+type MomentumDescent struct {
+	MonoGradientDescent
+	momentum float64   // fraction of the velocity kept at every iteration, usually 0.9
+	nesterov bool      // if the gradient is taken at the look-ahead point
+	velocity []float64 // the last move, after the projection
+}
+
+// newMomentumDescent Creates a MomentumDescent optimizer on the objective function of the problem
+func newMomentumDescent(start *Point, function int, tolerance float64, maxit uint, stepLength, momentum float64, nesterov bool) *MomentumDescent {
+	return &MomentumDescent{
+		MonoGradientDescent: MonoGradientDescent{current: start, function: function, tolerance: tolerance, maxit: maxit, stepLength: stepLength},
+		momentum:            momentum,
+		nesterov:            nesterov,
+		velocity:            make([]float64, len(start.inputs)),
+	}
+}
+
+func (o *MomentumDescent) move(current *Point) Point {
+	floats.Scale(o.momentum, o.velocity)
+	var grad []float64
+	if o.nesterov {
+		var n = len(current.inputs)
+		var ahead = current.Problem.project(floats.AddTo(make([]float64, n), current.inputs, o.velocity))
+		grad = append([]float64{}, current.Problem.jacobian(ahead).Data().([]float64)[o.function*n:(o.function+1)*n]...)
+	} else {
+		grad = gradientOf(current, o.function)
+	}
+	floats.AddScaled(o.velocity, -o.stepLength, grad)
+	var pt = o.moveTo(floats.AddTo(make([]float64, len(current.inputs)), current.inputs, o.velocity))
+	floats.SubTo(o.velocity, pt.inputs, current.inputs)
+	return pt
+}
+
+// AdaGrad : Gradient descent with one step per variable, stepLength divided by the root of the
+// sum of the squares of all its previous partial derivatives. The steps shrink along the
+// variables with steep gradients, which suits sparse or badly scaled problems.
+type AdaGrad struct {
+	MonoGradientDescent
+	epsilon float64   // avoids divisions by zero
+	squares []float64 // sum of the squared partial derivatives
+}
+
+// newAdaGrad Creates an AdaGrad optimizer on the objective function of the problem
+func newAdaGrad(start *Point, function int, tolerance float64, maxit uint, stepLength float64) *AdaGrad {
+	return &AdaGrad{
+		MonoGradientDescent: MonoGradientDescent{current: start, function: function, tolerance: tolerance, maxit: maxit, stepLength: stepLength},
+		epsilon:             1e-8,
+		squares:             make([]float64, len(start.inputs)),
+	}
+}
+
+func (o *AdaGrad) move(current *Point) Point {
+	var grad = gradientOf(current, o.function)
+	var x = append([]float64{}, current.inputs...)
+	for i, g := range grad {
+		o.squares[i] += g * g
+		x[i] -= o.stepLength * g / (math.Sqrt(o.squares[i]) + o.epsilon)
+	}
+	return o.moveTo(x)
+}
+
+// RMSProp : AdaGrad with an exponential moving average of the squared partial derivatives
+// instead of their sum, so that the steps do not vanish on long runs.
+type RMSProp struct {
+	MonoGradientDescent
+	decay   float64   // weight of the previous average, usually 0.9
+	epsilon float64   // avoids divisions by zero
+	squares []float64 // moving average of the squared partial derivatives
+}
+
+// newRMSProp Creates an RMSProp optimizer on the objective function of the problem
+func newRMSProp(start *Point, function int, tolerance float64, maxit uint, stepLength float64) *RMSProp {
+	return &RMSProp{
+		MonoGradientDescent: MonoGradientDescent{current: start, function: function, tolerance: tolerance, maxit: maxit, stepLength: stepLength},
+		decay:               0.9,
+		epsilon:             1e-8,
+		squares:             make([]float64, len(start.inputs)),
+	}
+}
+
+func (o *RMSProp) move(current *Point) Point {
+	var grad = gradientOf(current, o.function)
+	var x = append([]float64{}, current.inputs...)
+	for i, g := range grad {
+		o.squares[i] = o.decay*o.squares[i] + (1-o.decay)*g*g
+		x[i] -= o.stepLength * g / (math.Sqrt(o.squares[i]) + o.epsilon)
+	}
+	return o.moveTo(x)
+}
+
+// Adam : RMSProp with momentum: moving averages of both the partial derivatives and their
+// squares, corrected for their bias toward 0 in the first iterations.
+type Adam struct {
+	MonoGradientDescent
+	beta1, beta2 float64   // weights of the previous averages, usually 0.9 and 0.999
+	epsilon      float64   // avoids divisions by zero
+	moments      []float64 // moving average of the partial derivatives
+	squares      []float64 // moving average of their squares
+	iteration    int       // number of moves, for the bias correction
+}
+
+// newAdam Creates an Adam optimizer on the objective function of the problem
+func newAdam(start *Point, function int, tolerance float64, maxit uint, stepLength float64) *Adam {
+	return &Adam{
+		MonoGradientDescent: MonoGradientDescent{current: start, function: function, tolerance: tolerance, maxit: maxit, stepLength: stepLength},
+		beta1:               0.9,
+		beta2:               0.999,
+		epsilon:             1e-8,
+		moments:             make([]float64, len(start.inputs)),
+		squares:             make([]float64, len(start.inputs)),
+	}
+}
+
+func (o *Adam) move(current *Point) Point {
+	var grad = gradientOf(current, o.function)
+	var x = append([]float64{}, current.inputs...)
+	o.iteration++
+	var correction1 = 1 - math.Pow(o.beta1, float64(o.iteration))
+	var correction2 = 1 - math.Pow(o.beta2, float64(o.iteration))
+	for i, g := range grad {
+		o.moments[i] = o.beta1*o.moments[i] + (1-o.beta1)*g
+		o.squares[i] = o.beta2*o.squares[i] + (1-o.beta2)*g*g
+		x[i] -= o.stepLength * (o.moments[i] / correction1) / (math.Sqrt(o.squares[i]/correction2) + o.epsilon)
+	}
+	return o.moveTo(x)
+}
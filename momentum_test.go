@@ -0,0 +1,47 @@
+package main
+
+import (
+	"testing"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+func TestAcceleratedDescentsConverge(t *testing.T) {
+	// badly scaled quadratic, of minimizer (1, -2), or (1, -1) when y >= -1
+	var p = mustProblemFromEquations([]string{"(x-1)**2 + 10*(y+2)**2"}, "x", "y")
+	var bounded = p.withBounds([]float64{-5, -1}, []float64{5, 3})
+	var optimizers = []struct {
+		name string
+		new  func(start *Point) Optimizer
+	}{
+		{"momentum", func(s *Point) Optimizer { return newMomentumDescent(s, 0, 1e-6, 5000, 0.01, 0.9, false) }},
+		{"Nesterov", func(s *Point) Optimizer { return newMomentumDescent(s, 0, 1e-6, 5000, 0.01, 0.9, true) }},
+		{"AdaGrad", func(s *Point) Optimizer { return newAdaGrad(s, 0, 1e-6, 5000, 0.5) }},
+		{"RMSProp", func(s *Point) Optimizer { return newRMSProp(s, 0, 1e-6, 5000, 0.01) }},
+		{"Adam", func(s *Point) Optimizer { return newAdam(s, 0, 1e-6, 5000, 0.05) }},
+	}
+	var problems = []struct {
+		name    string
+		problem *Problem
+		want    []float64
+	}{
+		{"unbounded", &p, []float64{1, -2}},
+		{"minimizer on a bound", &bounded, []float64{1, -1}},
+	}
+	for _, c := range problems {
+		for _, o := range optimizers {
+			var start = c.problem.evaluate([]float64{-3, 2})
+			var trajectory = descend(o.new(&start))
+			var end = trajectory[len(trajectory)-1].inputs
+			if len(trajectory) > 5000 {
+				t.Errorf("%s, %s: no convergence in 5000 iterations", o.name, c.name)
+			}
+			if !floats.EqualApprox(end, c.want, 1e-5) {
+				t.Errorf("%s, %s: stops at %v, want %v", o.name, c.name, end, c.want)
+			}
+			if !c.problem.isFeasible(end) {
+				t.Errorf("%s, %s: %v is out of the box", o.name, c.name, end)
+			}
+		}
+	}
+}
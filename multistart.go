@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math/rand"
+	"os"
+	"runtime"
+	"strconv"
+	"sync"
+)
+
+// ##############################################################
+// Multi-start sampling of the Pareto front: a descent method converges to
+// one Pareto-critical point per start, so many runs from a space-filling
+// set of starting points approximate the whole Pareto set.
+// ##############################################################
+
+// latinHypercube n random points of the (finite) box of the problem, such that every variable
+// has exactly one point in each of the n slices of its range.
+func latinHypercube(p *Problem, n int, rng *rand.Rand) [][]float64 {
+	var points = make([][]float64, n)
+	for a := range points {
+		points[a] = make([]float64, p.nVars)
+	}
+	for i := 0; i < p.nVars; i++ {
+		for a, slice := range rng.Perm(n) {
+			var u = (float64(slice) + rng.Float64()) / float64(n)
+			points[a][i] = p.lower[i] + u*(p.upper[i]-p.lower[i])
+		}
+	}
+	return points
+}
+
+// haltonPoints The first n points of the Halton low-discrepancy sequence, scaled to the (finite)
+// box of the problem. The variable i uses the radical inverse in the base of the i-th prime number.
+func haltonPoints(p *Problem, n int) [][]float64 {
+	var primes = []int{}
+	for candidate := 2; len(primes) < p.nVars; candidate++ {
+		var isPrime = true
+		for _, q := range primes {
+			if candidate%q == 0 {
+				isPrime = false
+				break
+			}
+		}
+		if isPrime {
+			primes = append(primes, candidate)
+		}
+	}
+
+	var points = make([][]float64, n)
+	for a := range points {
+		points[a] = make([]float64, p.nVars)
+		for i, base := range primes {
+			// radical inverse of a+1 (0 would be the corner of the box)
+			var u, scale = 0.0, 1.0 / float64(base)
+			for index := a + 1; index > 0; index /= base {
+				u += float64(index%base) * scale
+				scale /= float64(base)
+			}
+			points[a][i] = p.lower[i] + u*(p.upper[i]-p.lower[i])
+		}
+	}
+	return points
+}
+
+// multiStart Runs an optimizer from every starting point (projected in the box if needed),
+// in parallel on the given number of goroutines (0 for one per CPU). Returns the non-dominated
+// endpoints, and the trajectory of every run in the order of the starting points.
+func multiStart(p *Problem, starts [][]float64, newOptimizer func(start *Point) Optimizer, workers int) ([]Point, [][]Point) {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	var trajectories = make([][]Point, len(starts))
+	var jobs = make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for a := range jobs {
+				first, err := p.startingPoint(starts[a], true)
+				if err != nil {
+					panic(err) // cannot happen, infeasible points are projected
+				}
+				trajectories[a] = descend(newOptimizer(&first))
+			}
+		}()
+	}
+	for a := range starts {
+		jobs <- a
+	}
+	close(jobs)
+	wg.Wait()
+
+	var archive = newParetoArchive(0, CrowdingPruning)
+	var endpoints = make([]Point, len(trajectories))
+	for a, trajectory := range trajectories {
+		endpoints[a] = trajectory[len(trajectory)-1]
+	}
+	archive.insertAll(endpoints)
+	fmt.Printf("%d runs, %d non-dominated endpoints.\n", len(starts), archive.size())
+	return archive.getMembers(), trajectories
+}
+
+// pointsToCSV Writes the points in a CSV file, one per line: the variables, then the objective values
+func pointsToCSV(points []Point, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	writer.Comma = ' '
+	for a := range points {
+		var line = []string{}
+		for _, x := range points[a].inputs {
+			line = append(line, strconv.FormatFloat(x, 'g', -1, 64))
+		}
+		for _, f := range imagesOf(&points[a]) {
+			line = append(line, strconv.FormatFloat(f, 'g', -1, 64))
+		}
+		if err = writer.Write(line); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// multiStartToCSV Writes the results of multiStart: the approximate Pareto set and front in
+// prefix_front.csv (sorted by the first objective), and every run in prefix_trajectoryN.csv.
+func multiStartToCSV(front []Point, trajectories [][]Point, prefix string) error {
+	var sorted = kungFilter(front) // sorts them lexicographically
+	if err := pointsToCSV(sorted, prefix+"_front.csv"); err != nil {
+		return err
+	}
+	for a, trajectory := range trajectories {
+		if err := pointsToCSV(trajectory, fmt.Sprintf("%s_trajectory%d.csv", prefix, a+1)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
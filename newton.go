@@ -0,0 +1,227 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+// ##############################################################
+// Multiobjective Newton method (Fliege, Graña Drummond & Svaiter, 2009).
+// The direction minimizes the largest quadratic model of the objectives:
+//
+//	d(x) = argmin_d max_k q_k(d),  q_k(d) = grad f_k(x)^T d + 1/2 d^T H_k(x) d
+//
+// and theta(x) = max_k q_k(d(x)) <= 0 is null only at Pareto-critical points.
+// ##############################################################
+
+// MultiobjectiveNewton : A multiobjective Newton method using the Hessians of all the objectives.
+// The non-convex Hessians are shifted to have their eigenvalues above minCurvature, and the
+// step is chosen by a line search from the full Newton step, which is accepted near a
+// Pareto-critical point with positive definite Hessians: the convergence is then quadratic.
+type MultiobjectiveNewton struct {
+	current          *Point       // starting point
+	tolerance        float64      // min |theta(x)| to continue iterating
+	maxit            uint         // max number of iterations before halt
+	minCurvature     float64      // smallest eigenvalue allowed in the Hessians
+	linesearch       LineSearcher // chooses the step, starting from the full Newton step
+	criticalDetected bool         // if no step decreases the objectives anymore
+	direction        []float64    // Newton direction at directionAt
+	theta            float64      // value of the subproblem at directionAt
+	directionAt      *Point       // the point where the direction was computed
+}
+
+// newMultiobjectiveNewton Creates a MultiobjectiveNewton optimizer with the usual safeguards
+func newMultiobjectiveNewton(start *Point, tolerance float64, maxit uint) *MultiobjectiveNewton {
+	return &MultiobjectiveNewton{
+		current:      start,
+		tolerance:    tolerance,
+		maxit:        maxit,
+		minCurvature: 1e-6,
+		linesearch:   newArmijoBacktracking(),
+	}
+}
+
+// newtonDirection Computes (and remembers) the Newton direction and theta at a point
+func (o *MultiobjectiveNewton) newtonDirection(pt *Point) ([]float64, float64) {
+	if o.directionAt != pt {
+		var n = pt.Problem.nVars
+		var hess = pt.Problem.hessian(pt.inputs).Data().([]float64)
+		var hessians = make([]*mat.SymDense, pt.Problem.nDims)
+		for k := range hessians {
+			var h = append([]float64{}, hess[k*n*n:(k+1)*n*n]...)
+			if !isFinite(h) {
+				// near a singularity of the objective: fall back to the steepest descent model
+				for i := range h {
+					h[i] = 0
+				}
+				for i := 0; i < n; i++ {
+					h[i*n+i] = 1
+				}
+			}
+			hessians[k] = convexified(mat.NewSymDense(n, h), o.minCurvature)
+		}
+		o.direction, o.theta, _ = minMaxQuadratic(gradientsOf(pt), hessians)
+		o.directionAt = pt
+	}
+	return o.direction, o.theta
+}
+
+func (o *MultiobjectiveNewton) move(current *Point) Point {
+	var d, _ = o.newtonDirection(current)
+	pt, ok := o.linesearch.search(current, d, 1, nil)
+	if !ok {
+		// No step decreases all the objectives, we are (numerically) Pareto-critical
+		o.criticalDetected = true
+		return *current
+	}
+	o.current = &pt
+	return pt
+}
+
+func (o *MultiobjectiveNewton) getCurrent() *Point {
+	return o.current
+}
+
+func (o *MultiobjectiveNewton) getLineSearcher() LineSearcher {
+	return o.linesearch
+}
+
+func (o *MultiobjectiveNewton) checkConverged(itNumber uint) bool {
+
+	// Check if we can't find descent steps anymore
+	if o.criticalDetected {
+		fmt.Printf("Let's stop, this point is Pareto-critical.\n")
+		return true
+	}
+
+	// Check if we ran for too long
+	if itNumber > o.maxit {
+		fmt.Printf("Stopping without convergence after %d iterations. =(\n", o.maxit)
+		return true
+	}
+
+	// Check if the Newton subproblem predicts no decrease anymore
+	_, theta := o.newtonDirection(o.current)
+	if math.Abs(theta) <= o.tolerance {
+		fmt.Printf("Newton decrement %g is below the tolerance threshold (%f), let's stop, we converged!\n", theta, o.tolerance)
+		return true
+	}
+
+	return false
+}
+
+// convexified Returns h, shifted by a multiple of the identity if needed for its
+// smallest eigenvalue to be at least minEigenvalue.
+func convexified(h *mat.SymDense, minEigenvalue float64) *mat.SymDense {
+	var eigen mat.EigenSym
+	if !eigen.Factorize(h, false) {
+		panic("eigendecomposition of a Hessian failed")
+	}
+	var lowest = floats.Min(eigen.Values(nil))
+	if lowest >= minEigenvalue {
+		return h
+	}
+	var shifted = mat.NewSymDense(h.Symmetric(), nil)
+	shifted.CopySym(h)
+	for i := 0; i < h.Symmetric(); i++ {
+		shifted.SetSym(i, i, h.At(i, i)+minEigenvalue-lowest)
+	}
+	return shifted
+}
+
+// minMaxQuadratic Solves min_d max_k g_k^T d + 1/2 d^T H_k d, the H_k being positive definite,
+// through its dual: max over the simplex of phi(lambda) = min_d sum_k lambda_k q_k(d), which is
+// reached at d(lambda) = -(sum_k lambda_k H_k)^-1 sum_k lambda_k g_k.
+// By Danskin's theorem, the gradient of phi is (q_k(d(lambda)))_k, so phi is maximized by projected
+// gradient ascent, until the duality gap max_k q_k(d) - phi(lambda) vanishes.
+// Returns d, the optimal value theta = max_k q_k(d), and the multipliers lambda.
+func minMaxQuadratic(grads [][]float64, hessians []*mat.SymDense) ([]float64, float64, []float64) {
+	var m, n = len(grads), len(grads[0])
+
+	// d(lambda), the values q_k(d(lambda)) and phi(lambda)
+	var solve = func(lambda []float64) ([]float64, []float64, float64) {
+		var h = mat.NewSymDense(n, nil)
+		var g = make([]float64, n)
+		for k := 0; k < m; k++ {
+			h.AddSym(h, scaledSym(lambda[k], hessians[k]))
+			floats.AddScaled(g, lambda[k], grads[k])
+		}
+		var chol mat.Cholesky
+		if !chol.Factorize(h) {
+			panic("the combined Hessian is not positive definite")
+		}
+		var dVec = mat.NewVecDense(n, nil)
+		if err := chol.SolveVecTo(dVec, mat.NewVecDense(n, g)); err != nil {
+			panic(err)
+		}
+		var d = make([]float64, n)
+		floats.ScaleTo(d, -1, dVec.RawVector().Data)
+
+		var q = make([]float64, m)
+		var hd = mat.NewVecDense(n, nil)
+		for k := 0; k < m; k++ {
+			hd.MulVec(hessians[k], mat.NewVecDense(n, d))
+			q[k] = floats.Dot(grads[k], d) + 0.5*floats.Dot(d, hd.RawVector().Data)
+		}
+		return d, q, floats.Dot(lambda, q)
+	}
+
+	var lambda = make([]float64, m)
+	for k := range lambda {
+		lambda[k] = 1 / float64(m)
+	}
+	var d, q, phi = solve(lambda)
+	var step = 1.0
+	for it := 0; it < 1000; it++ {
+		var theta = floats.Max(q)
+		if theta-phi <= 1e-12+1e-9*math.Abs(theta) {
+			break
+		}
+
+		// Ascent step, halved until phi increases
+		var improved = false
+		for ; step > 1e-16; step /= 2 {
+			var candidate = projectOnSimplex(floats.AddScaledTo(make([]float64, m), lambda, step, q))
+			var cd, cq, cphi = solve(candidate)
+			if cphi > phi {
+				lambda, d, q, phi = candidate, cd, cq, cphi
+				improved = true
+				step *= 2
+				break
+			}
+		}
+		if !improved {
+			break
+		}
+	}
+	return d, floats.Max(q), lambda
+}
+
+// scaledSym Returns c*a
+func scaledSym(c float64, a *mat.SymDense) *mat.SymDense {
+	var s = mat.NewSymDense(a.Symmetric(), nil)
+	s.ScaleSym(c, a)
+	return s
+}
+
+// projectOnSimplex The Euclidean projection of v on the unit simplex {lambda >= 0, sum lambda = 1}
+func projectOnSimplex(v []float64) []float64 {
+	var sorted = append([]float64{}, v...)
+	sort.Sort(sort.Reverse(sort.Float64Slice(sorted)))
+	var cumulated, shift = 0.0, 0.0
+	for i, vi := range sorted {
+		cumulated += vi
+		if t := (cumulated - 1) / float64(i+1); vi-t > 0 {
+			shift = t
+		}
+	}
+	var lambda = make([]float64, len(v))
+	for i := range v {
+		lambda[i] = math.Max(v[i]-shift, 0)
+	}
+	return lambda
+}
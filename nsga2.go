@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+)
+
+// ##############################################################
+// NSGA-II
+//
+// Reference: Deb, K., Pratap, A., Agarwal, S., Meyarivan, T.: A Fast and
+// Elitist Multiobjective Genetic Algorithm: NSGA-II. IEEE Transactions on
+// Evolutionary Computation 6(2) (2002), 182-197
+// ##############################################################
+
+// NSGA2 : The elitist non-dominated sorting genetic algorithm. Every generation, the offspring
+// are bred from parents chosen by binary tournaments, then the best half of the parents and
+// offspring survives: by non-domination rank, then by crowding distance to keep the front spread.
+// The constraints are handled by the constrained domination, the derivatives are not used.
+type NSGA2 struct {
+	problem        *Problem
+	population     []Point
+	maxGenerations uint       // number of generations before halt
+	crossoverRate  float64    // probability to recombine two parents
+	etaCrossover   float64    // distribution index of the SBX crossover
+	mutationRate   float64    // probability to mutate every variable
+	etaMutation    float64    // distribution index of the polynomial mutation
+	rng            *rand.Rand // seeded, for reproducible runs
+	rank           []int      // non-domination rank of the population, for the tournaments
+	crowding       []float64  // crowding distance of the population, for the tournaments
+}
+
+// newNSGA2 Creates an NSGA2 optimizer with a random initial population in the box of the problem,
+// and the usual parameters of the variation operators.
+func newNSGA2(p *Problem, size int, maxGenerations uint, seed int64) (*NSGA2, error) {
+	if err := checkFiniteBounds(p); err != nil {
+		return nil, err
+	}
+	if size < 4 {
+		return nil, fmt.Errorf("population of %d points is too small", size)
+	}
+	var o = &NSGA2{
+		problem:        p,
+		maxGenerations: maxGenerations,
+		crossoverRate:  0.9,
+		etaCrossover:   15,
+		mutationRate:   1 / float64(p.nVars),
+		etaMutation:    20,
+		rng:            rand.New(rand.NewSource(seed)),
+	}
+	var population = make([]Point, size)
+	for i := range population {
+		population[i] = p.evaluateObjectives(randomInBox(p, o.rng))
+	}
+	o.population, o.rank, o.crowding = survivors(population, size)
+	return o, nil
+}
+
+// survivors Keeps the n best points of the population, by rank then crowding distance,
+// and returns them with their rank and crowding distance.
+func survivors(population []Point, n int) ([]Point, []int, []float64) {
+	var kept = make([]Point, 0, n)
+	var rank = make([]int, 0, n)
+	var crowding = make([]float64, 0, n)
+	for r, front := range nonDominatedSort(population) {
+		var distance = crowdingDistance(population, front)
+		var order = make([]int, len(front))
+		for a := range order {
+			order[a] = a
+		}
+		if len(kept)+len(front) > n {
+			// the last front does not fit: keep its least crowded points
+			sort.SliceStable(order, func(a, b int) bool { return distance[order[a]] > distance[order[b]] })
+			order = order[:n-len(kept)]
+		}
+		for _, a := range order {
+			kept = append(kept, population[front[a]])
+			rank = append(rank, r)
+			crowding = append(crowding, distance[a])
+		}
+		if len(kept) == n {
+			break
+		}
+	}
+	return kept, rank, crowding
+}
+
+// tournament Picks two random points, and returns the index of the better one:
+// lower rank first, then larger crowding distance.
+func (o *NSGA2) tournament() int {
+	var a, b = o.rng.Intn(len(o.population)), o.rng.Intn(len(o.population))
+	if o.rank[b] < o.rank[a] || (o.rank[b] == o.rank[a] && o.crowding[b] > o.crowding[a]) {
+		return b
+	}
+	return a
+}
+
+func (o *NSGA2) evolve() []Point {
+	var size = len(o.population)
+	var merged = append([]Point{}, o.population...)
+	for len(merged) < 2*size {
+		var parent1 = o.population[o.tournament()].inputs
+		var parent2 = o.population[o.tournament()].inputs
+		var child1, child2 = append([]float64{}, parent1...), append([]float64{}, parent2...)
+		if o.rng.Float64() < o.crossoverRate {
+			child1, child2 = sbxCrossover(o.problem, parent1, parent2, o.etaCrossover, o.rng)
+		}
+		for _, child := range [][]float64{child1, child2} {
+			if len(merged) < 2*size {
+				polynomialMutation(o.problem, child, o.mutationRate, o.etaMutation, o.rng)
+				merged = append(merged, o.problem.evaluateObjectives(child))
+			}
+		}
+	}
+	o.population, o.rank, o.crowding = survivors(merged, size)
+	return o.population
+}
+
+func (o *NSGA2) getPopulation() []Point {
+	return o.population
+}
+
+func (o *NSGA2) checkConverged(generation uint) bool {
+	if generation >= o.maxGenerations {
+		fmt.Printf("Stopping after %d generations.\n", o.maxGenerations)
+		return true
+	}
+	return false
+}
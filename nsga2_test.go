@@ -0,0 +1,22 @@
+package main
+
+import "testing"
+
+// TestNSGA2OnZDT1 Runs NSGA-II on ZDT1 with the settings of Deb et al. (2002), and measures the
+// generational distance and the IGD to samples of the true front.
+func TestNSGA2OnZDT1(t *testing.T) {
+	var b = newZDT(1, 30)
+	var samples = b.front(500)
+	o, err := newNSGA2(&b.Problem, 100, 250, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	front, _ := runPopulation(o)
+	var images = imagesOfPoints(front)
+	if gd := generationalDistance(images, samples); !(gd < 0.005) {
+		t.Errorf("generational distance %g, want less than 0.005", gd)
+	}
+	if igd := invertedGenerationalDistance(images, samples); !(igd < 0.01) {
+		t.Errorf("IGD %g, want less than 0.01", igd)
+	}
+}
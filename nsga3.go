@@ -0,0 +1,283 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+// ##############################################################
+// NSGA-III, for many-objective problems
+//
+// Reference: Deb, K., Jain, H.: An Evolutionary Many-Objective Optimization
+// Algorithm Using Reference-Point-Based Nondominated Sorting Approach, Part I:
+// Solving Problems With Box Constraints. IEEE Transactions on Evolutionary
+// Computation 18(4) (2014), 577-601
+// ##############################################################
+
+// referenceDirections The Das and Dennis structured reference directions of the unit simplex,
+// with outer divisions on the boundary layer. With many objectives, the lattice has either too
+// many points or none inside the simplex: a second layer with inner divisions (0 for none) can
+// then be added, shrunk by half toward the centroid.
+func referenceDirections(m, outer, inner int) [][]float64 {
+	var directions = simplexLattice(m, outer)
+	if inner > 0 {
+		for _, w := range simplexLattice(m, inner) {
+			for k := range w {
+				w[k] = 0.5*w[k] + 0.5/float64(m)
+			}
+			directions = append(directions, w)
+		}
+	}
+	return directions
+}
+
+// NSGA3 : The reference-point based non-dominated sorting genetic algorithm. It works as NSGA2,
+// but the last front admitted in the next generation is not chosen with the crowding distance,
+// which loses its meaning with many objectives: the objectives are normalized, every point is
+// associated to its closest reference direction, and the points of the least crowded directions
+// (niches) are preferred.
+type NSGA3 struct {
+	problem        *Problem
+	population     []Point
+	references     [][]float64 // reference directions, on the unit simplex
+	maxGenerations uint        // number of generations before halt
+	crossoverRate  float64     // probability to recombine two parents
+	etaCrossover   float64     // distribution index of the SBX crossover
+	mutationRate   float64     // probability to mutate every variable
+	etaMutation    float64     // distribution index of the polynomial mutation
+	ideal          []float64   // best value found for each objective
+	extremes       [][]float64 // extreme points of the previous generation, one per objective
+	rng            *rand.Rand  // seeded, for reproducible runs
+}
+
+// newNSGA3 Creates an NSGA3 optimizer for the given reference directions (see referenceDirections),
+// with a random initial population in the box of the problem. As in the reference paper, the
+// population size is the smallest multiple of four above the number of directions.
+func newNSGA3(p *Problem, references [][]float64, maxGenerations uint, seed int64) (*NSGA3, error) {
+	if err := checkFiniteBounds(p); err != nil {
+		return nil, err
+	}
+	if len(references) == 0 || len(references[0]) != p.nDims {
+		return nil, fmt.Errorf("the reference directions must have %d coordinates", p.nDims)
+	}
+	var o = &NSGA3{
+		problem:        p,
+		references:     references,
+		maxGenerations: maxGenerations,
+		crossoverRate:  1,
+		etaCrossover:   30,
+		mutationRate:   1 / float64(p.nVars),
+		etaMutation:    20,
+		ideal:          make([]float64, p.nDims),
+		rng:            rand.New(rand.NewSource(seed)),
+	}
+	for k := range o.ideal {
+		o.ideal[k] = math.Inf(1)
+	}
+	o.population = make([]Point, (len(references)/4+1)*4)
+	for i := range o.population {
+		o.population[i] = p.evaluateObjectives(randomInBox(p, o.rng))
+	}
+	return o, nil
+}
+
+// normalize Translates the objective values of the points to the ideal point, and divides
+// them by the intercepts of the hyperplane through the extreme points (those with the lowest
+// achievement scalarizing function along each axis). When this hyperplane is degenerate,
+// the intercepts are the worst values of the non-dominated points instead.
+func (o *NSGA3) normalize(images [][]float64, nonDominated []int) [][]float64 {
+	var m = o.problem.nDims
+	for _, f := range images {
+		for k := range f {
+			o.ideal[k] = math.Min(o.ideal[k], f[k])
+		}
+	}
+
+	// Extreme points, among the points and the previous extremes
+	var candidates = append(append([][]float64{}, images...), o.extremes...)
+	var extremes = make([][]float64, m)
+	for axis := 0; axis < m; axis++ {
+		var best = math.Inf(1)
+		for _, f := range candidates {
+			var asf = math.Inf(-1)
+			for k := range f {
+				var w = 1e-6
+				if k == axis {
+					w = 1
+				}
+				asf = math.Max(asf, (f[k]-o.ideal[k])/w)
+			}
+			if asf < best {
+				best, extremes[axis] = asf, f
+			}
+		}
+	}
+	o.extremes = extremes
+
+	// Intercepts of the hyperplane: solve (E - z) b = 1, the intercepts being 1/b
+	var intercepts = make([]float64, m)
+	var e = mat.NewDense(m, m, nil)
+	for a := 0; a < m; a++ {
+		for k := 0; k < m; k++ {
+			e.Set(a, k, extremes[a][k]-o.ideal[k])
+		}
+	}
+	var ones = make([]float64, m)
+	floats.AddConst(1, ones)
+	var b = mat.NewVecDense(m, nil)
+	var degenerate = b.SolveVec(e, mat.NewVecDense(m, ones)) != nil
+	for k := 0; k < m && !degenerate; k++ {
+		intercepts[k] = 1 / b.AtVec(k)
+		degenerate = math.IsNaN(intercepts[k]) || intercepts[k] <= 1e-6
+	}
+	if degenerate {
+		for k := range intercepts {
+			intercepts[k] = math.Inf(-1)
+			for _, i := range nonDominated {
+				intercepts[k] = math.Max(intercepts[k], images[i][k]-o.ideal[k])
+			}
+			if intercepts[k] <= 1e-6 {
+				intercepts[k] = 1
+			}
+		}
+	}
+
+	var normalized = make([][]float64, len(images))
+	for i, f := range images {
+		normalized[i] = make([]float64, m)
+		for k := range f {
+			normalized[i][k] = (f[k] - o.ideal[k]) / intercepts[k]
+		}
+	}
+	return normalized
+}
+
+// associate Returns the closest reference direction of every normalized point,
+// and its perpendicular distance to it.
+func (o *NSGA3) associate(normalized [][]float64) ([]int, []float64) {
+	var niche = make([]int, len(normalized))
+	var distance = make([]float64, len(normalized))
+	for i, f := range normalized {
+		distance[i] = math.Inf(1)
+		for j, w := range o.references {
+			var along = floats.Dot(f, w) / floats.Dot(w, w)
+			var d = floats.Distance(f, floats.ScaleTo(make([]float64, len(w)), along, w), 2)
+			if d < distance[i] {
+				niche[i], distance[i] = j, d
+			}
+		}
+	}
+	return niche, distance
+}
+
+func (o *NSGA3) evolve() []Point {
+	var size = len(o.population)
+
+	// Offspring, from random parents
+	var merged = append([]Point{}, o.population...)
+	for len(merged) < 2*size {
+		var parent1 = o.population[o.rng.Intn(size)].inputs
+		var parent2 = o.population[o.rng.Intn(size)].inputs
+		var child1, child2 = append([]float64{}, parent1...), append([]float64{}, parent2...)
+		if o.rng.Float64() < o.crossoverRate {
+			child1, child2 = sbxCrossover(o.problem, parent1, parent2, o.etaCrossover, o.rng)
+		}
+		for _, child := range [][]float64{child1, child2} {
+			if len(merged) < 2*size {
+				polynomialMutation(o.problem, child, o.mutationRate, o.etaMutation, o.rng)
+				merged = append(merged, o.problem.evaluateObjectives(child))
+			}
+		}
+	}
+
+	// Admit whole fronts while they fit
+	var fronts = nonDominatedSort(merged)
+	var candidates, last = []int{}, []int{}
+	for _, front := range fronts {
+		if len(candidates)+len(front) > size {
+			last = front
+			break
+		}
+		candidates = append(candidates, front...)
+	}
+	var next = make([]Point, 0, size)
+	for _, i := range candidates {
+		next = append(next, merged[i])
+	}
+	if len(last) > 0 {
+		// Niching: choose the points of the last front in the least crowded niches
+		var considered = append(append([]int{}, candidates...), last...)
+		var images = make([][]float64, len(considered))
+		for a, i := range considered {
+			images[a] = imagesOf(&merged[i])
+		}
+		var firstFront = make([]int, len(fronts[0]))
+		for a := range firstFront {
+			firstFront[a] = a // the first front comes first in considered
+		}
+		if len(fronts[0]) > len(considered) {
+			firstFront = firstFront[:len(considered)]
+		}
+		var niche, distance = o.associate(o.normalize(images, firstFront))
+
+		var count = make([]int, len(o.references))
+		for a := range candidates {
+			count[niche[a]]++
+		}
+		var members = make(map[int][]int) // niche -> positions in considered, of the last front
+		for a := len(candidates); a < len(considered); a++ {
+			members[niche[a]] = append(members[niche[a]], a)
+		}
+		var excluded = make([]bool, len(o.references))
+		for len(next) < size {
+			// a random niche among the least crowded ones
+			var lowest, least = math.MaxInt32, []int{}
+			for j := range o.references {
+				if excluded[j] {
+					continue
+				}
+				if count[j] < lowest {
+					lowest, least = count[j], []int{j}
+				} else if count[j] == lowest {
+					least = append(least, j)
+				}
+			}
+			var j = least[o.rng.Intn(len(least))]
+			if len(members[j]) == 0 {
+				excluded[j] = true
+				continue
+			}
+
+			// the closest member of an empty niche, a random member otherwise
+			var pick = o.rng.Intn(len(members[j]))
+			if count[j] == 0 {
+				for c, a := range members[j] {
+					if distance[a] < distance[members[j][pick]] {
+						pick = c
+					}
+				}
+			}
+			next = append(next, merged[considered[members[j][pick]]])
+			members[j] = append(members[j][:pick], members[j][pick+1:]...)
+			count[j]++
+		}
+	}
+	o.population = next
+	return o.population
+}
+
+func (o *NSGA3) getPopulation() []Point {
+	return o.population
+}
+
+func (o *NSGA3) checkConverged(generation uint) bool {
+	if generation >= o.maxGenerations {
+		fmt.Printf("Stopping after %d generations.\n", o.maxGenerations)
+		return true
+	}
+	return false
+}
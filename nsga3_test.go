@@ -0,0 +1,41 @@
+package main
+
+import (
+	"testing"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+// TestNSGA3OnDTLZ2 Runs NSGA-III on DTLZ2 with the settings of Deb & Jain (2014), and measures
+// the IGD to the targeted points: the intersections of the reference directions with the
+// spherical Pareto front.
+func TestNSGA3OnDTLZ2(t *testing.T) {
+	var cases = []struct {
+		nObjectives, outer, inner int
+		generations               uint
+		maxIGD                    float64
+	}{
+		{3, 12, 0, 250, 0.005},
+		{8, 3, 2, 500, 0.04},
+	}
+	for _, c := range cases {
+		if testing.Short() && c.nObjectives > 3 {
+			continue
+		}
+		var b = newDTLZ(2, c.nObjectives, 0)
+		var references = referenceDirections(c.nObjectives, c.outer, c.inner)
+		var targets = make([][]float64, len(references))
+		for a, w := range references {
+			targets[a] = append([]float64{}, w...)
+			floats.Scale(1/floats.Norm(w, 2), targets[a])
+		}
+		o, err := newNSGA3(&b.Problem, references, c.generations, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		front, _ := runPopulation(o)
+		if igd := invertedGenerationalDistance(imagesOfPoints(front), targets); !(igd < c.maxIGD) {
+			t.Errorf("DTLZ2 with %d objectives: IGD %g, want less than %g", c.nObjectives, igd, c.maxIGD)
+		}
+	}
+}
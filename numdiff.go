@@ -0,0 +1,348 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"gorgonia.org/tensor"
+)
+
+// ##############################################################
+// Numerical derivatives, used when a Problem supplies neither its
+// derivatives nor an ADFunction, and to check the supplied ones.
+// ##############################################################
+
+// DiffMethod A numerical differentiation scheme.
+type DiffMethod uint8
+
+// The available numerical differentiation schemes
+const (
+	CentralDifferences DiffMethod = iota // error in O(h²), 2M evaluations of f
+	ForwardDifferences                   // error in O(h), M+1 evaluations of f
+	ComplexStep                          // exact up to rounding, needs Problem.fComplex (else central differences are used)
+)
+
+// FiniteDifferences The settings of the numerical derivatives of a Problem.
+// The zero value uses central differences with an automatic step.
+type FiniteDifferences struct {
+	method DiffMethod
+	step   float64 // relative step (absolute for ComplexStep), 0 for the default of the method
+}
+
+// Default steps, balancing the truncation and rounding errors of each scheme
+var (
+	defaultCentralStep = math.Cbrt(epsilon)            // for first derivatives with central differences
+	defaultForwardStep = math.Sqrt(epsilon)            // for first derivatives with forward differences
+	defaultComplexStep = 1e-20                         // the complex step does not suffer from cancellation
+	defaultSecondStep  = math.Sqrt(math.Sqrt(epsilon)) // for second derivatives of f
+)
+
+const epsilon = 2.220446049250313e-16 // the float64 machine epsilon
+
+// stepAt Returns the step to use on a variable currently equal to xi
+func (fd FiniteDifferences) stepAt(xi float64, defaultStep float64) float64 {
+	var h = fd.step
+	if h == 0 {
+		h = defaultStep
+	}
+	return h * math.Max(1, math.Abs(xi))
+}
+
+// values Returns the objective values at x as a slice
+func (p *Problem) values(x []float64) []float64 {
+	return p.objectives(x).Data().([]float64)
+}
+
+// numericalJacobian Approximates the Jacobian matrix at x, R^M -> M(N, M)
+func (p *Problem) numericalJacobian(x []float64) *tensor.Dense {
+	var nVars, nDims = len(x), p.nDims
+	var backing = make([]float64, nDims*nVars)
+	var method = p.numdiff.method
+	if method == ComplexStep && p.fComplex == nil {
+		method = CentralDifferences
+	}
+
+	switch method {
+	case ComplexStep:
+		var h = p.numdiff.step
+		if h == 0 {
+			h = defaultComplexStep
+		}
+		var z = make([]complex128, nVars)
+		for i := range x {
+			z[i] = complex(x[i], 0)
+		}
+		for j := range x {
+			z[j] = complex(x[j], h)
+			for k, fz := range p.fComplex(z) {
+				backing[k*nVars+j] = imag(fz) / h
+			}
+			z[j] = complex(x[j], 0)
+		}
+	case ForwardDifferences:
+		var xh = append([]float64{}, x...)
+		var f0 = append([]float64{}, p.values(x)...)
+		for j := range x {
+			xh[j] = x[j] + p.numdiff.stepAt(x[j], defaultForwardStep)
+			var h = xh[j] - x[j] // the step actually representable
+			for k, fk := range p.values(xh) {
+				backing[k*nVars+j] = (fk - f0[k]) / h
+			}
+			xh[j] = x[j]
+		}
+	default:
+		var xh = append([]float64{}, x...)
+		for j := range x {
+			var h = p.numdiff.stepAt(x[j], defaultCentralStep)
+			xh[j] = x[j] + h
+			var fp = append([]float64{}, p.values(xh)...)
+			xh[j] = x[j] - h
+			var fm = p.values(xh)
+			for k := range fp {
+				backing[k*nVars+j] = (fp[k] - fm[k]) / (2 * h)
+			}
+			xh[j] = x[j]
+		}
+	}
+	return tensor.New(tensor.WithShape(nDims, nVars), tensor.WithBacking(backing))
+}
+
+// hasExactJacobian Tells if the Jacobian of p is known up to rounding errors
+func (p *Problem) hasExactJacobian() bool {
+	return p.jacobianf != nil || p.ad != nil || (p.numdiff.method == ComplexStep && p.fComplex != nil)
+}
+
+// numericalHessian Approximates the Hessian matrices at x, R^M -> T(N,M,M).
+// If the Jacobian is exact, it is differentiated with central differences,
+// otherwise the second derivatives of f are approximated directly.
+func (p *Problem) numericalHessian(x []float64) *tensor.Dense {
+	if p.hasExactJacobian() {
+		// the step of p.numdiff may be a complex step, too small for real differences
+		return jacobianDifferences(p.jacobian, x, p.nDims, FiniteDifferences{})
+	}
+
+	var nVars, nDims = len(x), p.nDims
+	var backing = make([]float64, nDims*nVars*nVars)
+	var xh = append([]float64{}, x...)
+	var f0 = append([]float64{}, p.values(x)...)
+	var h = make([]float64, nVars)
+	for j := range x {
+		h[j] = p.numdiff.stepAt(x[j], defaultSecondStep)
+	}
+
+	// f(x + hj + hk) - f(x + hj - hk) - f(x - hj + hk) + f(x - hj - hk) = 4 hj hk d2f/dxjdxk
+	var shifted = func(j, k int, sj, sk float64) []float64 {
+		xh[j] += sj * h[j]
+		xh[k] += sk * h[k]
+		var fx = append([]float64{}, p.values(xh)...)
+		xh[j], xh[k] = x[j], x[k]
+		return fx
+	}
+	for j := 0; j < nVars; j++ {
+		for k := j; k < nVars; k++ {
+			var fpp, fmm []float64
+			var fpm, fmp []float64
+			if j == k {
+				fpp, fmm = shifted(j, j, 1, 1), shifted(j, j, -1, -1) // x +- 2hj
+				fpm, fmp = f0, f0
+			} else {
+				fpp, fmm = shifted(j, k, 1, 1), shifted(j, k, -1, -1)
+				fpm, fmp = shifted(j, k, 1, -1), shifted(j, k, -1, 1)
+			}
+			for i := 0; i < nDims; i++ {
+				var value = (fpp[i] - fpm[i] - fmp[i] + fmm[i]) / (4 * h[j] * h[k])
+				backing[(i*nVars+j)*nVars+k] = value
+				backing[(i*nVars+k)*nVars+j] = value
+			}
+		}
+	}
+	return tensor.New(tensor.WithShape(nDims, nVars, nVars), tensor.WithBacking(backing))
+}
+
+// jacobianDifferences Approximates the Hessian matrices by central differences
+// of a Jacobian function, then symmetrizes them.
+func jacobianDifferences(jacobian func([]float64) *tensor.Dense, x []float64, nDims int, fd FiniteDifferences) *tensor.Dense {
+	var nVars = len(x)
+	var backing = make([]float64, nDims*nVars*nVars)
+	var xh = append([]float64{}, x...)
+	for k := range x {
+		var h = fd.stepAt(x[k], defaultCentralStep)
+		xh[k] = x[k] + h
+		var jp = append([]float64{}, jacobian(xh).Data().([]float64)...)
+		xh[k] = x[k] - h
+		var jm = jacobian(xh).Data().([]float64)
+		xh[k] = x[k]
+		for i := 0; i < nDims; i++ {
+			for j := 0; j < nVars; j++ {
+				backing[(i*nVars+j)*nVars+k] = (jp[i*nVars+j] - jm[i*nVars+j]) / (2 * h)
+			}
+		}
+	}
+	for i := 0; i < nDims; i++ {
+		for j := 0; j < nVars; j++ {
+			for k := j + 1; k < nVars; k++ {
+				var a, b = &backing[(i*nVars+j)*nVars+k], &backing[(i*nVars+k)*nVars+j]
+				*a = 0.5 * (*a + *b)
+				*b = *a
+			}
+		}
+	}
+	return tensor.New(tensor.WithShape(nDims, nVars, nVars), tensor.WithBacking(backing))
+}
+
+// ##############################################################
+// Derivative checker
+// ##############################################################
+
+// Relative errors above which a supplied derivative is reported as wrong. The errors of the
+// order of the rounding errors of the numerical derivatives are within the tolerance: for a badly
+// scaled function, they can be much larger than the derivatives.
+const (
+	jacobianCheckTolerance = 1e-5
+	hessianCheckTolerance  = 1e-4
+	reportedMismatches     = 5 // number of worst entries kept in a DerivativeReport
+)
+
+// derivativeMismatch One entry of a supplied derivative compared to its numerical value.
+// For the Jacobian, the entry is dF(objective)/dx(i), and j is -1.
+type derivativeMismatch struct {
+	objective, i, j int
+	supplied        float64
+	numerical       float64
+	relativeError   float64 // |supplied - numerical| / max(1, |numerical|, noise / tolerance)
+}
+
+func (m derivativeMismatch) String() string {
+	var entry = fmt.Sprintf("dF%d/dx%d", m.objective+1, m.i+1)
+	if m.j >= 0 {
+		entry = fmt.Sprintf("d2F%d/dx%ddx%d", m.objective+1, m.i+1, m.j+1)
+	}
+	return fmt.Sprintf("%s: supplied %g, numerical %g (relative error %.2e)", entry, m.supplied, m.numerical, m.relativeError)
+}
+
+// DerivativeReport The result of CheckDerivatives: the worst entries of the
+// supplied Jacobian and Hessian matrices, compared to numerical approximations.
+type DerivativeReport struct {
+	x                []float64
+	checkedJacobian  bool
+	checkedHessian   bool
+	jacobianWorst    []derivativeMismatch // sorted by decreasing relative error
+	hessianWorst     []derivativeMismatch // sorted by decreasing relative error
+	maxJacobianError float64
+	maxHessianError  float64
+}
+
+// ok Tells if all the supplied derivatives match their numerical approximations
+func (r DerivativeReport) ok() bool {
+	return r.maxJacobianError <= jacobianCheckTolerance && r.maxHessianError <= hessianCheckTolerance
+}
+
+func (r DerivativeReport) String() string {
+	var s = fmt.Sprintf("Derivatives checked at %g:\n", r.x)
+	var section = func(name string, checked bool, maxError, tolerance float64, worst []derivativeMismatch) {
+		switch {
+		case !checked:
+			s += fmt.Sprintf("  %s: not supplied, nothing to check.\n", name)
+		case maxError <= tolerance:
+			s += fmt.Sprintf("  %s: OK (max relative error %.2e).\n", name, maxError)
+		default:
+			s += fmt.Sprintf("  %s: MISMATCH (max relative error %.2e), worst entries:\n", name, maxError)
+			for _, m := range worst {
+				if m.relativeError > tolerance {
+					s += "    " + m.String() + "\n"
+				}
+			}
+		}
+	}
+	section("Jacobian", r.checkedJacobian, r.maxJacobianError, jacobianCheckTolerance, r.jacobianWorst)
+	section("Hessian", r.checkedHessian, r.maxHessianError, hessianCheckTolerance, r.hessianWorst)
+	return s
+}
+
+// worstMismatches Compares two flattened tensors entry by entry and returns
+// the worst entries, and the largest relative error. noise is the rounding error
+// expected in each numerical entry.
+func worstMismatches(supplied, numerical, noise []float64, tolerance float64, nVars int, isHessian bool) ([]derivativeMismatch, float64) {
+	var all = make([]derivativeMismatch, len(supplied))
+	var maxError = 0.0
+	for n := range supplied {
+		var m = derivativeMismatch{supplied: supplied[n], numerical: numerical[n], j: -1}
+		if isHessian {
+			m.objective, m.i, m.j = n/(nVars*nVars), (n/nVars)%nVars, n%nVars
+		} else {
+			m.objective, m.i = n/nVars, n%nVars
+		}
+		m.relativeError = math.Abs(m.supplied-m.numerical) / math.Max(math.Max(1, math.Abs(m.numerical)), noise[n]/tolerance)
+		if math.IsNaN(m.relativeError) {
+			m.relativeError = math.Inf(1)
+		}
+		maxError = math.Max(maxError, m.relativeError)
+		all[n] = m
+	}
+	sort.SliceStable(all, func(a, b int) bool { return all[a].relativeError > all[b].relativeError })
+	if len(all) > reportedMismatches {
+		all = all[:reportedMismatches]
+	}
+	return all, maxError
+}
+
+// CheckDerivatives Compares the Jacobian and Hessian supplied by a Problem
+// (by hand or by automatic differentiation) with numerical approximations at x.
+// The Hessian is compared with the central differences of the supplied Jacobian
+// when it is right, or with an approximation from the objective values only.
+func CheckDerivatives(p *Problem, x []float64) DerivativeReport {
+	var report = DerivativeReport{x: x}
+	var nVars = len(x)
+
+	// The reference Jacobian uses the most accurate method available
+	var reference = *p
+	reference.jacobianf, reference.hessianf, reference.ad = nil, nil, nil
+	reference.numdiff = FiniteDifferences{method: CentralDifferences}
+	if p.fComplex != nil {
+		reference.numdiff.method = ComplexStep
+	}
+	if p.f == nil { // the objectives are only known through the ADFunction
+		reference.f = func(v []float64) *tensor.Dense { return adObjectives(p.ad, v) }
+	}
+
+	var values = reference.values(x)
+	if p.jacobianf != nil || p.ad != nil {
+		report.checkedJacobian = true
+		var supplied = p.jacobian(x).Data().([]float64)
+		var numerical = reference.numericalJacobian(x).Data().([]float64)
+		// rounding errors of central differences: epsilon |f| / h
+		var noise = make([]float64, len(numerical))
+		for n := range noise {
+			var h = FiniteDifferences{}.stepAt(x[n%nVars], defaultCentralStep)
+			noise[n] = epsilon * math.Abs(values[n/nVars]) / h
+		}
+		report.jacobianWorst, report.maxJacobianError = worstMismatches(supplied, numerical, noise, jacobianCheckTolerance, nVars, false)
+	}
+
+	if p.hessianf != nil || p.ad != nil {
+		report.checkedHessian = true
+		var supplied = p.hessian(x).Data().([]float64)
+		var numerical []float64
+		var noise = make([]float64, len(supplied))
+		if report.checkedJacobian && report.maxJacobianError <= jacobianCheckTolerance {
+			// rounding errors of the central differences of the Jacobian: epsilon |gradient| / h
+			var jacobian = p.jacobian(x).Data().([]float64)
+			numerical = jacobianDifferences(p.jacobian, x, p.nDims, FiniteDifferences{}).Data().([]float64)
+			for n := range noise {
+				var k, i, j = n / (nVars * nVars), (n / nVars) % nVars, n % nVars
+				var h = math.Min(FiniteDifferences{}.stepAt(x[i], defaultCentralStep), FiniteDifferences{}.stepAt(x[j], defaultCentralStep))
+				noise[n] = epsilon * math.Max(math.Abs(jacobian[k*nVars+i]), math.Abs(jacobian[k*nVars+j])) / h
+			}
+		} else {
+			// rounding errors of the second differences of f: epsilon |f| / (hi hj)
+			numerical = reference.numericalHessian(x).Data().([]float64)
+			for n := range noise {
+				var k, i, j = n / (nVars * nVars), (n / nVars) % nVars, n % nVars
+				noise[n] = epsilon * math.Abs(values[k]) / (reference.numdiff.stepAt(x[i], defaultSecondStep) * reference.numdiff.stepAt(x[j], defaultSecondStep))
+			}
+		}
+		report.hessianWorst, report.maxHessianError = worstMismatches(supplied, numerical, noise, hessianCheckTolerance, nVars, true)
+	}
+	return report
+}
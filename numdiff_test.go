@@ -0,0 +1,43 @@
+package main
+
+import (
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/optimize/functions"
+	"gorgonia.org/tensor"
+)
+
+func TestNumericalHessianWithComplexStep(t *testing.T) {
+	// f = x³y + exp(y): the Jacobian by complex step is exact, the Hessian is its central differences
+	var pb = mustProblemFromEquations([]string{"x**3*y + exp(y)"}, "x", "y")
+	pb.jacobianf, pb.hessianf = nil, nil
+	pb.numdiff = FiniteDifferences{ComplexStep, 1e-20}
+	var x, y = 1.3, 0.4
+	var want = []float64{6 * x * y, 3 * x * x, 3 * x * x, math.Exp(y)}
+	for i, got := range pb.hessian([]float64{x, y}).Data().([]float64) {
+		if !closeTo(got, want[i], 1e-6) {
+			t.Errorf("Hessian entry %d: %g, want %g", i, got, want[i])
+		}
+	}
+}
+
+func TestCheckDerivativesBadlyScaled(t *testing.T) {
+	// f is about 1e12 there: the rounding errors of the numerical derivatives are large
+	var pb = newProblemFromGonum(functions.BrownBadlyScaled{}, 2)
+	var x = []float64{30682.195787138564, 1.9606174961260998e-07}
+	if report := CheckDerivatives(&pb, x); !report.ok() {
+		t.Errorf("right derivatives reported wrong:\n%v", report)
+	}
+
+	// a wrong gradient is still reported
+	var jacobian = pb.jacobianf
+	pb.jacobianf = func(x []float64) *tensor.Dense {
+		var j = jacobian(x)
+		j.Data().([]float64)[1] *= 1.01
+		return j
+	}
+	if report := CheckDerivatives(&pb, x); report.ok() {
+		t.Errorf("wrong derivatives reported right:\n%v", report)
+	}
+}
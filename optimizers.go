@@ -2,10 +2,8 @@ package main
 
 import (
 	"fmt"
-	"time"
 
-	"gonum.org/v1/gonum/optimize"
-	"gorgonia.org/tensor"
+	"gonum.org/v1/gonum/floats"
 )
 
 // Optimizer : A type providing all the necessary methods to iterate
@@ -23,11 +21,13 @@ type Optimizer interface {
 // MonoGradientDescent : A simple optimizer which only considers the first
 // objective function, and moves following its -gradient to valleys.
 type MonoGradientDescent struct {
-	current    *Point  // Starting point
-	function   int     // function of the multiobjective problem to use (use 0 if mono-objective)
-	tolerance  float64 // min gradient norm to continue iterating
-	maxit      uint    // max number of iterations before halt
-	stepLength float64 // how much we move at every iteration
+	current          *Point       // Starting point
+	function         int          // function of the multiobjective problem to use (use 0 if mono-objective)
+	tolerance        float64      // min gradient norm to continue iterating
+	maxit            uint         // max number of iterations before halt
+	criticalDetected bool         // if the line search cannot decrease the function anymore
+	stepLength       float64      // how much we move at every iteration (the first trial step with a line search)
+	linesearch       LineSearcher // optional, see linesearch.go. nil to always move by stepLength
 }
 
 func (o *MonoGradientDescent) move(current *Point) Point {
@@ -35,14 +35,29 @@ func (o *MonoGradientDescent) move(current *Point) Point {
 	copy(x, current.inputs)
 
 	// We loop on the variables (axis)
+	var direction = make([]float64, len(x))
 	for i := range x {
 		// the descent direction on axis i is -dF1/dxi
 		g, err := current.gradient.At(o.function, i)
 		if err != nil {
 			panic(err)
 		}
-		x[i] -= o.stepLength * g.(float64)
+		direction[i] = -g.(float64)
 	}
+
+	if o.linesearch != nil {
+		pt, ok := o.linesearch.search(current, direction, o.stepLength, []int{o.function})
+		if !ok {
+			o.criticalDetected = true
+		}
+		o.current = &pt
+		return pt
+	}
+
+	for i := range x {
+		x[i] += o.stepLength * direction[i]
+	}
+	x = current.Problem.project(x) // stay in the box constraints, if any
 	// fmt.Println("--------------------------------------------------------------")
 	// fmt.Printf("--> Now moving from %.2f to %.2f\n", current.inputs, x)
 
@@ -55,8 +70,18 @@ func (o *MonoGradientDescent) getCurrent() *Point {
 	return o.current
 }
 
+func (o *MonoGradientDescent) getLineSearcher() LineSearcher {
+	return o.linesearch
+}
+
 func (o *MonoGradientDescent) checkConverged(itNumber uint) bool {
 
+	// Check if the line search failed to decrease the function
+	if o.criticalDetected {
+		fmt.Printf("Let's stop, no step decreases the function anymore.\n")
+		return true
+	}
+
 	// Check if we ran for too long
 	if itNumber > o.maxit {
 		fmt.Printf("Stopping without convergence after %d iterations. =(\n", o.maxit)
@@ -64,9 +89,10 @@ func (o *MonoGradientDescent) checkConverged(itNumber uint) bool {
 	}
 
 	// Check if any of the functions have a null gradient vector
-	for f := 0; f < p.nDims; f++ {
-		gradfNorm, _ := o.current.gradNorm.At(f)
-		if gradfNorm.(float64) <= o.tolerance {
+	// (projected on the box constraints, if any)
+	for f := 0; f < o.current.Problem.nDims; f++ {
+		gradfNorm := projectedGradientNorm(o.current, f)
+		if gradfNorm <= o.tolerance {
 			fmt.Printf("Gradient of norm %f is below the tolerance threshold (%f), let's stop, we converged!\n", gradfNorm, o.tolerance)
 			return true
 		}
@@ -76,84 +102,73 @@ func (o *MonoGradientDescent) checkConverged(itNumber uint) bool {
 }
 
 // SteepestDescent : A multiobjective gradient descent chosing the steepest direction
-// to decrease the value of an objective.
+// to decrease the value of all the objectives (see minNormDirection in qp.go).
 type SteepestDescent struct {
-	current          *Point  // starting point
-	tolerance        float64 // min gradient norm to continue iteraring
-	maxit            uint    // max number of iterations before halt
-	criticalDetected bool    // if we cannot find a descent direction anymore
-	stepLength       float64 // how much we move at every iteration
+	current          *Point       // starting point
+	tolerance        float64      // min norm of the steepest direction to continue iteraring
+	maxit            uint         // max number of iterations before halt
+	criticalDetected bool         // if we cannot find a descent direction anymore
+	stepLength       float64      // how much we move at every iteration (the first trial step with a line search)
+	linesearch       LineSearcher // optional, see linesearch.go. nil to always move by stepLength
 }
 
-func (o *SteepestDescent) move(current *Point) Point {
-	var x = make([]float64, len(current.inputs))
-	copy(x, current.inputs)
-
-	// We search for a descent direction.
-	// Define the optimization problem
-	problem := optimize.Problem{
-		Func: func(x []float64) float64 {
-			var direction = make([]float64, len(x))
-			copy(direction, x)
-
-			// transform the input direction into a vector
-			var d = tensor.New(tensor.WithShape(len(x)), tensor.WithBacking(direction))
-			dNorm := toFloat(d.Norm(2, 1))
-			fmt.Printf("\tVector %f has squared norm %f.\n", d, dNorm*dNorm)
-
-			// Get the slope (or "Frechet derivative, look how i'm smart and i know words")
-			// Of every function in direction d
-			slope, _ := current.gradient.MatVecMul(d)
-			fmt.Printf("\tGradient in this direction: %.2f\n", slope)
-
-			// Get the steepest slope
-			a := toFloat(slope.Max(1))
-
-			// return the unconstrained optimization problem value
-			fmt.Printf("\tValue is %.2f + %.2f = %.2f\n\n", a, 0.5*dNorm*dNorm, a+0.5*dNorm*dNorm)
-			return a + 0.5*dNorm*dNorm
-		},
-
-		Grad: func(grad, x []float64) {
-			copy(grad, x) // dProblem/dd = dd for all indices that are not the steepest descent
-
-			// transform the input direction into a vector
-			var d = tensor.New(tensor.WithShape(len(x)), tensor.WithBacking(grad)) // d1, d2, ..., dm
-			slope, _ := current.gradient.MatVecMul(d)                              // sum_j(dFk/dj*dj)
-
-			// Get the steepest slope index
-			temp, _ := slope.Argmax(0)
-			j := temp.Get(0).(int)
-			grad[j] += slope.Get(j).(float64)
-			fmt.Printf("\tGradient of the problem in %f is %f, its maximum is %f.\n", x, grad, grad[j])
-		},
+// steepestDirection The steepest common descent direction at a point, and the criticality
+// measure theta = -1/2 |d|². The variables on a bound of the box, which the direction would
+// push outside, are frozen (their component is null) and the direction is computed again.
+func steepestDirection(pt *Point) ([]float64, float64) {
+	var pb = pt.Problem
+	var grads = gradientsOf(pt)
+	if !pb.isBounded() {
+		var d, theta, _ = minNormDirection(grads)
+		return d, theta
 	}
 
-	// Propose a direction descent as init value
-	var init = make([]float64, p.nVars)
-	for i := range init {
-		init[i] = -1
+	for k := range grads {
+		grads[k] = append([]float64{}, grads[k]...) // do not modify the Jacobian of the point
 	}
-
-	// Solve the optimization problem
-	settings := optimize.Settings{InitValues: nil, GradientThreshold: o.tolerance, Converger: nil, MajorIterations: 100000, Runtime: 0, FuncEvaluations: 0, GradEvaluations: 0, HessEvaluations: 0, Recorder: nil, Concurrent: 2}
-	result, _ := optimize.Minimize(problem, init, &settings, &optimize.NelderMead{})
-	if result.F > 0 {
-		o.criticalDetected = true
+	for {
+		var d, theta, _ = minNormDirection(grads)
+		var blocked = false
+		for i := range d {
+			if (d[i] < 0 && pt.inputs[i] <= pb.lower[i]) || (d[i] > 0 && pt.inputs[i] >= pb.upper[i]) {
+				blocked = true
+				for k := range grads {
+					grads[k][i] = 0
+				}
+			}
+		}
+		if !blocked {
+			return d, theta
+		}
 	}
-	fmt.Printf("Found descent direction: %v (problem value = %f)\n", result.X, result.F)
+}
+
+func (o *SteepestDescent) move(current *Point) Point {
+	var x = make([]float64, len(current.inputs))
+	copy(x, current.inputs)
+
+	// The direction is the minimum-norm element of the convex hull of the -gradients
+	var direction, _ = steepestDirection(current)
 
 	// Now, update the point
+	if o.linesearch != nil {
+		pt, ok := o.linesearch.search(current, direction, o.stepLength, nil)
+		if !ok {
+			o.criticalDetected = true
+		}
+		o.current = &pt
+		return pt
+	}
 	// We loop on the variables (axis)
 	for i := range x {
-		x[i] = o.stepLength * result.X[i]
+		x[i] += o.stepLength * direction[i]
 	}
+	x = current.Problem.project(x) // stay in the box constraints, if any
 	// fmt.Println("--------------------------------------------------------------")
 	// fmt.Printf("--> Now moving from %.2f to %.2f\n", current.inputs, x)
 
 	var pt = current.Problem.evaluate(x)
 	o.current = &pt
-	time.Sleep(time.Second)
 	return pt
 }
 
@@ -161,6 +176,10 @@ func (o *SteepestDescent) getCurrent() *Point {
 	return o.current
 }
 
+func (o *SteepestDescent) getLineSearcher() LineSearcher {
+	return o.linesearch
+}
+
 func (o *SteepestDescent) checkConverged(itNumber uint) bool {
 
 	// Check if we can't find descent directions anymore
@@ -175,13 +194,11 @@ func (o *SteepestDescent) checkConverged(itNumber uint) bool {
 		return true
 	}
 
-	// Check if any of the functions have a null gradient vector
-	for f := 0; f < p.nDims; f++ {
-		gradfNorm, _ := o.current.gradNorm.At(f)
-		if gradfNorm.(float64) <= o.tolerance {
-			fmt.Printf("Gradient of norm %f is below the tolerance threshold (%f), let's stop, we converged!\n", gradfNorm, o.tolerance)
-			return true
-		}
+	// Check if the steepest direction vanishes (the point is Pareto-critical)
+	direction, theta := steepestDirection(o.current)
+	if dNorm := floats.Norm(direction, 2); dNorm <= o.tolerance {
+		fmt.Printf("Steepest direction of norm %f (theta = %g) is below the tolerance threshold (%f), let's stop, we converged!\n", dNorm, theta, o.tolerance)
+		return true
 	}
 
 	return false
@@ -11,22 +11,24 @@ import (
 
 // Point A struct to store all the information about a point in the problem's spaces.
 type Point struct {
-	inputs   []float64
-	images   *tensor.Dense
-	gradient *tensor.Dense
-	gradNorm *tensor.Dense
-	Problem  *Problem
+	inputs    []float64
+	images    *tensor.Dense
+	gradient  *tensor.Dense
+	gradNorm  *tensor.Dense
+	Problem   *Problem
+	violation float64 // largest violation of the constraints of the problem, 0 if feasible
 }
 
 func (p *Problem) evaluate(newInputs []float64) Point {
-	var grad = p.jacobianf(newInputs) // M(N,M)
-	var norm, _ = grad.Norm(2, 1)     // order 2 norms on axis 0, should be in R^N
+	var grad = p.jacobian(newInputs) // M(N,M)
+	var norm, _ = grad.Norm(2, 1)    // order 2 norms on axis 0, should be in R^N
 	var pt = Point{
 		newInputs,
-		p.f(newInputs),
+		p.objectives(newInputs),
 		grad,
 		norm,
 		p,
+		p.constraintViolation(newInputs),
 	}
 	// fmt.Printf("New point X: F(%.2f) = %.2f (grad norms are now: %.2f)\n", pt.inputs, pt.images, pt.gradNorm)
 	// fmt.Println()
@@ -34,3 +36,25 @@ func (p *Problem) evaluate(newInputs []float64) Point {
 	// fmt.Println()
 	return pt
 }
+
+// gradientsOf Returns the gradients of the objectives at a point, as the rows of its Jacobian
+func gradientsOf(pt *Point) [][]float64 {
+	var jac = pt.gradient.Data().([]float64)
+	var n = pt.Problem.nVars
+	var grads = make([][]float64, pt.Problem.nDims)
+	for k := range grads {
+		grads[k] = jac[k*n : (k+1)*n]
+	}
+	return grads
+}
+
+// evaluateObjectives Evaluates a point without its derivatives (gradient and gradNorm are nil),
+// for the methods which do not use them.
+func (p *Problem) evaluateObjectives(newInputs []float64) Point {
+	return Point{
+		inputs:    newInputs,
+		images:    p.objectives(newInputs),
+		Problem:   p,
+		violation: p.constraintViolation(newInputs),
+	}
+}
@@ -0,0 +1,23 @@
+package main
+
+import "fmt"
+
+// PopulationOptimizer : A type providing the methods to evolve a whole population of points,
+// to approximate the Pareto front in one run (the Optimizer interface follows a single point).
+type PopulationOptimizer interface {
+	evolve() []Point // computes the next generation, and returns it
+	getPopulation() []Point
+	checkConverged(generation uint) bool
+}
+
+// runPopulation Evolves the population until convergence. Returns the non-dominated points of
+// the last generation, and the snapshots of all the generations (the initial population first).
+func runPopulation(o PopulationOptimizer) ([]Point, [][]Point) {
+	var snapshots = [][]Point{o.getPopulation()}
+	for !o.checkConverged(uint(len(snapshots) - 1)) {
+		snapshots = append(snapshots, o.evolve())
+	}
+	var front = nonDominatedPoints(o.getPopulation())
+	fmt.Printf("Evolved %d generations, %d non-dominated points found.\n", len(snapshots)-1, len(front))
+	return front, snapshots
+}
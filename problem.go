@@ -1,8 +1,6 @@
 package main
 
 import (
-	"math"
-
 	"gorgonia.org/tensor"
 )
 
@@ -11,7 +9,10 @@ import (
 // ########################################################
 
 // Problem The struct which stores the optimisation problem definition,
-// by defining its function and derivatives
+// by defining its function and derivatives.
+// The functions can be written by hand, or derived from the equations with newProblemFromEquations.
+// Alternatively, f, jacobianf and hessianf can be left nil if ad is given (see autodiff.go).
+// If jacobianf or hessianf are nil without ad, they are approximated numerically (see numdiff.go).
 type Problem struct {
 	nVars     int
 	nDims     int
@@ -19,72 +20,80 @@ type Problem struct {
 	jacobianf func([]float64) *tensor.Dense
 	hessianf  func([]float64) *tensor.Dense
 	equations *[]string
+	ad        ADFunction
+	fComplex  func([]complex128) []complex128 // optional, f on complex inputs, for the complex-step method
+	numdiff   FiniteDifferences               // how to approximate the missing derivatives
+	lower     []float64                       // optional lower bounds of the variables (see bounds.go)
+	upper     []float64                       // optional upper bounds of the variables
+
+	// Optional constraints g(x) <= 0 and h(x) = 0, and their Jacobians (see constraints.go)
+	nIneq        int
+	nEq          int
+	ineq         func([]float64) *tensor.Dense // R^M -> R^nIneq
+	ineqJacobian func([]float64) *tensor.Dense // R^M -> M(nIneq, M)
+	eq           func([]float64) *tensor.Dense // R^M -> R^nEq
+	eqJacobian   func([]float64) *tensor.Dense // R^M -> M(nEq, M)
 }
 
-// Replace the numbers below to match the number of variables and number of objective functions in your problem.
+// Write the equations of your problem below, with gnuplot syntax, and name the variables they use.
+// The function to minimize (R^M -> R^N), its gradients (R^M -> M(N, M)) and its Hessian
+// matrices (R^M -> T(N,M,M)) are all derived from these strings (see expressions.go and symbolic.go).
 var eq = []string{"(x-y)**3+2*x**2+y**2-x+2*y-500", "x**4 - x**3 -20*x**2 + x + y**4 - y**3 -20*y**2 + y - 100"}
-var p = Problem{2, 2, f, jacobianf, hessianf, &eq}
-
-// The function to minimize, R^M -> R^N
-func f(v []float64) *tensor.Dense {
-	x, y := v[0], v[1]
-	xsq := x * x
-	ysq := y * y
-
-	f1 := math.Pow(x-y, 3) + 2.0*xsq + ysq - x + 2.0*y - 500.0
-	f2 := xsq*xsq - xsq*x - 20.0*xsq + x + math.Pow(ysq, 2) - ysq*y - 20.0*ysq + y - 100
-	var result = tensor.New(tensor.WithShape(2, 1), tensor.WithBacking([]float64{f1, f2}))
+var p = mustProblemFromEquations(eq, "x", "y").withBounds([]float64{-5, -5}, []float64{5, 5})
 
-	return result
+// Getter for the number of dimensions of the optimisation problem
+func (p *Problem) getDims() (int, int) {
+	return p.nVars, p.nDims
 }
 
-// Its gradients (partial derivatives) at some point, R^M -> M(N, M)
-func jacobianf(v []float64) *tensor.Dense {
-	x, y := v[0], v[1]
-	xsq := x * x
-	ysq := y * y
-
-	df1dx := 3.0*xsq - 2.0*x*y + 3.0*ysq - 1.0
-	df2dx := 4.0*xsq*x - 3.0*xsq - 40.0*x + 1.0
-	df1dy := -3.0*ysq - 3.0*xsq + 6.0*x*y + 2.0*y + 2.0
-	df2dy := 4.0*ysq*y - 3.0*ysq - 40.0*y + 1.0
-	var result = tensor.New(tensor.WithShape(2, 2), tensor.WithBacking([]float64{df1dx, df1dy, df2dx, df2dy}))
-
-	return result
+// Getter for the equations of the problem, nil if they are unknown
+func (p *Problem) getEquations() []string {
+	if p.equations == nil {
+		return nil
+	}
+	return *p.equations
 }
 
-// Its Hessian matrix computed at some point, R^M -> T(N,M,M)
-func hessianf(v []float64) *tensor.Dense {
-	x, y := v[0], v[1]
-
-	df1dxdx := 6.0*x - 2.0*y
-	df1dxdy := -2.0*x + 6.0*y
-	df1dydx := df1dxdy
-	df1dydy := -6.0*y + 6.0*x + 2.0
-	df2dxdx := 12.0*x*x - 6.0*x - 40.0
-	df2dxdy := 0.0
-	df2dydx := df1dxdy
-	df2dydy := 12.0*y*y - 6.0*y - 40.0
-	var result = tensor.New(tensor.WithShape(2, 2, 2), tensor.WithBacking([]float64{df1dxdx, df1dxdy, df1dydx, df1dydy, df2dxdx, df2dxdy, df2dydx, df2dydy}))
+// The objective values at some point, R^M -> R^N
+func (p *Problem) objectives(x []float64) *tensor.Dense {
+	if p.f == nil {
+		return adObjectives(p.ad, x)
+	}
+	return p.f(x)
+}
 
-	return result
+// The Jacobian matrix at some point, R^M -> M(N, M)
+func (p *Problem) jacobian(x []float64) *tensor.Dense {
+	switch {
+	case p.jacobianf != nil:
+		return p.jacobianf(x)
+	case p.ad != nil:
+		return adJacobian(p.ad, x)
+	}
+	return p.numericalJacobian(x)
 }
 
-// Getter for the number of dimensions of the optimisation problem
-func (p *Problem) getDims() (int, int) {
-	return p.nVars, p.nDims
+// The Hessian matrices at some point, R^M -> T(N,M,M)
+func (p *Problem) hessian(x []float64) *tensor.Dense {
+	switch {
+	case p.hessianf != nil:
+		return p.hessianf(x)
+	case p.ad != nil:
+		return adHessian(p.ad, x)
+	}
+	return p.numericalHessian(x)
 }
 
 // ##############################################################
 // 			OR USE ONE OF THESE KNOWN FUNCTIONS
 // ##############################################################
 
-// Code copy pasted and adapted without permission from gonum/optimize/functions.
+// Adapted from gonum/optimize/functions, the derivatives being derived from the equations.
 
 var bealeEq = []string{"(1.5-x+x*y)**2+(2.25-x+x*y*y)**2+(2.625-x+x*y*y*y)**2"}
 
 // BealeProblem implements the Beale's function.
-var BealeProblem = Problem{2, 1, beale, jacobianBeale, hessianBeale, &bealeEq}
+var BealeProblem = mustProblemFromEquations(bealeEq, "x", "y").withBounds([]float64{-4.5, -4.5}, []float64{4.5, 4.5})
 
 //
 // Standard starting points:
@@ -97,42 +106,3 @@ var BealeProblem = Problem{2, 1, beale, jacobianBeale, hessianBeale, &bealeEq}
 //    Techniques Research Group, Princeton University (1958)
 //  - More, J., Garbow, B.S., Hillstrom, K.E.: Testing unconstrained
 //    optimization software. ACM Trans Math Softw 7 (1981), 17-41
-
-func beale(x []float64) *tensor.Dense {
-	f1 := 1.5 - x[0]*(1-x[1])
-	f2 := 2.25 - x[0]*(1-x[1]*x[1])
-	f3 := 2.625 - x[0]*(1-x[1]*x[1]*x[1])
-	var result = tensor.New(tensor.WithShape(1, 1), tensor.WithBacking([]float64{f1*f1 + f2*f2 + f3*f3}))
-
-	return result
-}
-
-func jacobianBeale(x []float64) *tensor.Dense {
-	t1 := 1 - x[1]
-	t2 := 1 - x[1]*x[1]
-	t3 := 1 - x[1]*x[1]*x[1]
-
-	f1 := 1.5 - x[0]*t1
-	f2 := 2.25 - x[0]*t2
-	f3 := 2.625 - x[0]*t3
-
-	grad1 := -2 * (f1*t1 + f2*t2 + f3*t3)
-	grad2 := 2 * x[0] * (f1 + 2*f2*x[1] + 3*f3*x[1]*x[1])
-	var result = tensor.New(tensor.WithShape(1, 2), tensor.WithBacking([]float64{grad1, grad2}))
-	return result
-}
-
-func hessianBeale(x []float64) *tensor.Dense {
-	t1 := 1 - x[1]
-	t2 := 1 - x[1]*x[1]
-	t3 := 1 - x[1]*x[1]*x[1]
-	f1 := 1.5 - x[1]*t1
-	f2 := 2.25 - x[1]*t2
-	f3 := 2.625 - x[1]*t3
-
-	h00 := 2 * (t1*t1 + t2*t2 + t3*t3)
-	h01 := 2 * (f1 + x[1]*(2*f2+3*x[1]*f3) - x[0]*(t1+x[1]*(2*t2+3*x[1]*t3)))
-	h11 := 2 * x[0] * (x[0] + 2*f2 + x[1]*(6*f3+x[0]*x[1]*(4+9*x[1]*x[1])))
-	var result = tensor.New(tensor.WithShape(1, 2, 2), tensor.WithBacking([]float64{h00, h01, h01, h11}))
-	return result
-}
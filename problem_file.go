@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ##############################################################
+// Problems defined in JSON or YAML files, loaded at runtime:
+//
+//	{
+//	  "variables": [{"name": "x", "lower": -5, "upper": 5}, {"name": "y"}],
+//	  "objectives": ["(x-y)**3+2*x**2+y**2-x+2*y-500", "x**4+y**4-20*x**2-20*y**2"],
+//	  "inequalities": ["x+y-1"],
+//	  "equalities": ["x-2*y"],
+//	  "startingPoints": [[1, 4], [-2, 0]]
+//	}
+//
+// or, in a .yaml or .yml file:
+//
+//	variables:
+//	  - {name: x, lower: -5, upper: 5}
+//	  - name: y
+//	objectives:
+//	  - (x-y)**3+2*x**2+y**2-x+2*y-500
+//	  - x**4+y**4-20*x**2-20*y**2
+//	inequalities: [x+y-1]
+//	startingPoints: [[1, 4], [-2, 0]]
+//
+// The equations use the gnuplot syntax of expressions.go, their derivatives
+// are derived symbolically. The inequalities are g(x) <= 0, the equalities
+// h(x) = 0, both are optional, as are the bounds and the starting points.
+// ##############################################################
+
+// problemFile The content of a problem definition file
+type problemFile struct {
+	Variables []struct {
+		Name  string   `json:"name" yaml:"name"`
+		Lower *float64 `json:"lower" yaml:"lower"` // no lower bound if missing
+		Upper *float64 `json:"upper" yaml:"upper"` // no upper bound if missing
+	} `json:"variables" yaml:"variables"`
+	Objectives     []string    `json:"objectives" yaml:"objectives"`
+	Inequalities   []string    `json:"inequalities" yaml:"inequalities"`
+	Equalities     []string    `json:"equalities" yaml:"equalities"`
+	StartingPoints [][]float64 `json:"startingPoints" yaml:"startingPoints"`
+}
+
+// loadProblemFile Reads a problem definition file, in YAML if its extension is .yaml
+// or .yml, in JSON otherwise, and returns the Problem and the suggested starting
+// points (possibly none).
+func loadProblemFile(path string) (Problem, [][]float64, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Problem{}, nil, err
+	}
+	var def problemFile
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.UnmarshalStrict(content, &def) // catch the typos in the field names
+	default:
+		var decoder = json.NewDecoder(bytes.NewReader(content))
+		decoder.DisallowUnknownFields() // catch the typos in the field names
+		err = decoder.Decode(&def)
+	}
+	if err != nil {
+		return Problem{}, nil, fmt.Errorf("%s: %v", path, err)
+	}
+	pb, err := def.toProblem()
+	if err != nil {
+		return Problem{}, nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return pb, def.StartingPoints, nil
+}
+
+// toProblem Builds the Problem defined in the file
+func (def *problemFile) toProblem() (Problem, error) {
+	if len(def.Variables) == 0 {
+		return Problem{}, fmt.Errorf("no variables defined")
+	}
+	if len(def.Objectives) == 0 {
+		return Problem{}, fmt.Errorf("no objectives defined")
+	}
+
+	var names = make([]string, len(def.Variables))
+	var lower, upper = make([]float64, len(names)), make([]float64, len(names))
+	var bounded = false
+	for i, v := range def.Variables {
+		if v.Name == "" {
+			return Problem{}, fmt.Errorf("variable %d has no name", i+1)
+		}
+		for j := 0; j < i; j++ {
+			if names[j] == v.Name {
+				return Problem{}, fmt.Errorf("variables %d and %d are both named %s", j+1, i+1, v.Name)
+			}
+		}
+		names[i] = v.Name
+		lower[i], upper[i] = math.Inf(-1), math.Inf(1)
+		if v.Lower != nil {
+			lower[i], bounded = *v.Lower, true
+		}
+		if v.Upper != nil {
+			upper[i], bounded = *v.Upper, true
+		}
+		if lower[i] > upper[i] {
+			return Problem{}, fmt.Errorf("variable %s has an empty domain [%g, %g]", v.Name, lower[i], upper[i])
+		}
+	}
+
+	pb, err := newProblemFromEquations(def.Objectives, names...)
+	if err != nil {
+		return Problem{}, err
+	}
+	if bounded {
+		pb = pb.withBounds(lower, upper)
+	}
+	if len(def.Inequalities) > 0 {
+		g, jacobian, err := constraintsFromEquations(def.Inequalities, names...)
+		if err != nil {
+			return Problem{}, err
+		}
+		pb = pb.withInequalities(len(def.Inequalities), g, jacobian)
+	}
+	if len(def.Equalities) > 0 {
+		h, jacobian, err := constraintsFromEquations(def.Equalities, names...)
+		if err != nil {
+			return Problem{}, err
+		}
+		pb = pb.withEqualities(len(def.Equalities), h, jacobian)
+	}
+
+	for k, start := range def.StartingPoints {
+		if len(start) != len(names) {
+			return Problem{}, fmt.Errorf("starting point %d has %d coordinates, expected %d", k+1, len(start), len(names))
+		}
+	}
+	return pb, nil
+}
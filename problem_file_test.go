@@ -0,0 +1,65 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadProblemFileFormats(t *testing.T) {
+	jsonProblem, jsonStarts, err := loadProblemFile("problems/constrained_example.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	yamlProblem, yamlStarts, err := loadProblemFile("problems/constrained_example.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(jsonStarts, yamlStarts) {
+		t.Errorf("starting points %v in JSON, %v in YAML", jsonStarts, yamlStarts)
+	}
+	if !reflect.DeepEqual(jsonProblem.lower, yamlProblem.lower) || !reflect.DeepEqual(jsonProblem.upper, yamlProblem.upper) {
+		t.Errorf("bounds [%v, %v] in JSON, [%v, %v] in YAML", jsonProblem.lower, jsonProblem.upper, yamlProblem.lower, yamlProblem.upper)
+	}
+	var x = []float64{0.5, -1.5}
+	if a, b := jsonProblem.values(x), yamlProblem.values(x); !reflect.DeepEqual(a, b) {
+		t.Errorf("objectives %v in JSON, %v in YAML", a, b)
+	}
+	ja, _ := jsonProblem.constraintValues(x)
+	ya, _ := yamlProblem.constraintValues(x)
+	if !reflect.DeepEqual(ja, ya) || len(ja) != 1 {
+		t.Errorf("inequalities %v in JSON, %v in YAML", ja, ya)
+	}
+}
+
+func TestLoadProblemFileErrors(t *testing.T) {
+	var dir, err = ioutil.TempDir("", "problems")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var cases = []struct {
+		file, content, message string
+	}{
+		{"duplicate.json", `{"variables": [{"name": "x"}, {"name": "x"}], "objectives": ["x"]}`, "variables 1 and 2 are both named x"},
+		{"duplicate.yaml", "variables: [{name: x}, {name: y}, {name: y}]\nobjectives: [x+y]\n", "variables 2 and 3 are both named y"},
+		{"typo.yaml", "variables: [{name: x}]\nobjective: [x]\n", "objective"},
+		{"typo.json", `{"variables": [{"name": "x"}], "objective": ["x"]}`, "objective"},
+		{"empty.yml", "variables: [{name: x, lower: 2, upper: 1}]\nobjectives: [x]\n", "empty domain"},
+		{"start.yaml", "variables: [{name: x}]\nobjectives: [x]\nstartingPoints: [[1, 2]]\n", "starting point 1 has 2 coordinates"},
+	}
+	for _, c := range cases {
+		var path = filepath.Join(dir, c.file)
+		if err := ioutil.WriteFile(path, []byte(c.content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		_, _, err := loadProblemFile(path)
+		if err == nil || !strings.Contains(err.Error(), c.message) {
+			t.Errorf("%s: error %v, want %q", c.file, err, c.message)
+		}
+	}
+}
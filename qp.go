@@ -0,0 +1,135 @@
+package main
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+// ##############################################################
+// Quadratic programs on the unit simplex, the duals of the
+// direction-finding subproblems of the multiobjective descent methods.
+// ##############################################################
+
+// simplexQP Solves min 1/2 lambda^T Q lambda + c^T lambda s.t. lambda >= 0, sum lambda = 1,
+// Q being symmetric positive semidefinite, with a primal active-set method.
+// Starting from the best vertex, it solves the equality-constrained problem on the free
+// set of weights, walks toward its solution while it stays feasible (freeing the weights
+// which become null), and adds the weight with the most negative reduced gradient when the
+// solution is optimal on its free set. A tiny ridge makes the systems nonsingular when Q is
+// (for instance with more objectives than variables).
+func simplexQP(q *mat.SymDense, c []float64) []float64 {
+	var m = q.Symmetric()
+	var ridge = 0.0
+	for i := 0; i < m; i++ {
+		ridge = math.Max(ridge, q.At(i, i))
+	}
+	ridge = 1e-12 * (1 + ridge)
+
+	// Start at the best vertex
+	var lambda = make([]float64, m)
+	var free = make([]bool, m)
+	var best, bestValue = 0, math.Inf(1)
+	for i := 0; i < m; i++ {
+		if v := 0.5*q.At(i, i) + c[i]; v < bestValue {
+			best, bestValue = i, v
+		}
+	}
+	lambda[best], free[best] = 1, true
+
+	const tol = 1e-12
+	for it := 0; it < 10*m+100; it++ {
+		var mu = equalityQP(q, c, free, ridge)
+
+		// Walk toward mu while lambda stays nonnegative
+		var alpha, blocking = 1.0, -1
+		for i := range mu {
+			if free[i] && mu[i] < 0 {
+				if a := lambda[i] / (lambda[i] - mu[i]); a < alpha {
+					alpha, blocking = a, i
+				}
+			}
+		}
+		for i := range lambda {
+			if free[i] {
+				lambda[i] += alpha * (mu[i] - lambda[i])
+			}
+		}
+		if blocking >= 0 {
+			lambda[blocking], free[blocking] = 0, false
+			continue
+		}
+
+		// Optimal on the free set: check the reduced gradients of the other weights
+		var grad = make([]float64, m)
+		mat.NewVecDense(m, grad).MulVec(q, mat.NewVecDense(m, lambda))
+		floats.Add(grad, c)
+		var nu = floats.Dot(lambda, grad) // the multiplier of sum lambda = 1
+		var entering, mostNegative = -1, -tol * (1 + math.Abs(nu))
+		for i := range grad {
+			if !free[i] && grad[i]-nu < mostNegative {
+				entering, mostNegative = i, grad[i]-nu
+			}
+		}
+		if entering < 0 {
+			break
+		}
+		free[entering] = true
+	}
+	return lambda
+}
+
+// equalityQP Solves min 1/2 lambda^T (Q + ridge I) lambda + c^T lambda s.t. sum lambda = 1,
+// on the free weights only (the others being null), through its KKT system.
+func equalityQP(q *mat.SymDense, c []float64, free []bool, ridge float64) []float64 {
+	var index = []int{}
+	for i, f := range free {
+		if f {
+			index = append(index, i)
+		}
+	}
+	var nf = len(index)
+	var kkt = mat.NewDense(nf+1, nf+1, nil)
+	var rhs = mat.NewVecDense(nf+1, nil)
+	for a, i := range index {
+		for b, j := range index {
+			kkt.Set(a, b, q.At(i, j))
+		}
+		kkt.Set(a, a, kkt.At(a, a)+ridge)
+		kkt.Set(a, nf, 1)
+		kkt.Set(nf, a, 1)
+		rhs.SetVec(a, -c[i])
+	}
+	rhs.SetVec(nf, 1)
+
+	var solution = mat.NewVecDense(nf+1, nil)
+	if err := solution.SolveVec(kkt, rhs); err != nil {
+		panic(err)
+	}
+	var mu = make([]float64, len(free))
+	for a, i := range index {
+		mu[i] = solution.AtVec(a)
+	}
+	return mu
+}
+
+// minNormDirection The steepest common descent direction d = -sum_k lambda_k g_k, minus the element
+// of minimal norm of the convex hull of the gradients (Désidéri's MGDA). d is the solution of
+// min_d max_k g_k^T d + 1/2 |d|², whose optimal value theta = -1/2 |d|² is null only at
+// Pareto-critical points. Returns d, theta and the weights lambda.
+func minNormDirection(grads [][]float64) ([]float64, float64, []float64) {
+	var m, n = len(grads), len(grads[0])
+	var gram = mat.NewSymDense(m, nil)
+	for a := 0; a < m; a++ {
+		for b := a; b < m; b++ {
+			gram.SetSym(a, b, floats.Dot(grads[a], grads[b]))
+		}
+	}
+	var lambda = simplexQP(gram, make([]float64, m))
+	var d = make([]float64, n)
+	for k := range grads {
+		floats.AddScaled(d, -lambda[k], grads[k])
+	}
+	return d, -0.5 * floats.Dot(d, d), lambda
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+// ##############################################################
+// Multiobjective quasi-Newton methods: the Newton direction (see newton.go),
+// with approximations of the Hessians built from the successive gradients.
+//
+// References:
+//  - Povalej, Ž.: Quasi-Newton's method for multiobjective optimization.
+//    Journal of Computational and Applied Mathematics 255 (2014), 765-777
+//  - Lapucci, M., Mansueto, P.: A limited memory Quasi-Newton approach for
+//    multi-objective optimization. Computational Optimization and
+//    Applications 85 (2023), 33-73
+// ##############################################################
+
+// MultiobjectiveBFGS : The multiobjective Newton method with one BFGS approximation of the Hessian
+// per objective, starting from the identity. The updates are damped (Powell) so that the
+// approximations stay positive definite even when the objectives are not convex.
+type MultiobjectiveBFGS struct {
+	current          *Point          // starting point
+	tolerance        float64         // min |theta(x)| to continue iterating
+	maxit            uint            // max number of iterations before halt
+	linesearch       LineSearcher    // chooses the step, starting from the full quasi-Newton step
+	criticalDetected bool            // if no step decreases the objectives anymore
+	hessians         []*mat.SymDense // B_k, the approximation of the Hessian of each objective
+	direction        []float64       // quasi-Newton direction at directionAt
+	theta            float64         // value of the subproblem at directionAt
+	directionAt      *Point          // the point where the direction was computed
+}
+
+// newMultiobjectiveBFGS Creates a MultiobjectiveBFGS optimizer with identity Hessian approximations
+func newMultiobjectiveBFGS(start *Point, tolerance float64, maxit uint) *MultiobjectiveBFGS {
+	var n = start.Problem.nVars
+	var o = &MultiobjectiveBFGS{
+		current:    start,
+		tolerance:  tolerance,
+		maxit:      maxit,
+		linesearch: newArmijoBacktracking(),
+		hessians:   make([]*mat.SymDense, start.Problem.nDims),
+	}
+	for k := range o.hessians {
+		o.hessians[k] = mat.NewSymDense(n, nil)
+		for i := 0; i < n; i++ {
+			o.hessians[k].SetSym(i, i, 1)
+		}
+	}
+	return o
+}
+
+// quasiNewtonDirection Computes (and remembers) the direction and theta at a point
+func (o *MultiobjectiveBFGS) quasiNewtonDirection(pt *Point) ([]float64, float64) {
+	if o.directionAt != pt {
+		o.direction, o.theta, _ = minMaxQuadratic(gradientsOf(pt), o.hessians)
+		o.directionAt = pt
+	}
+	return o.direction, o.theta
+}
+
+// dampedBFGSUpdate Updates B with the step s and the gradient change y:
+// B + y y^T / s^T y - B s s^T B / s^T B s, y being first mixed with B s when
+// s^T y < 0.2 s^T B s (Powell's damping), to keep B positive definite.
+func dampedBFGSUpdate(b *mat.SymDense, s, y []float64) {
+	var n = len(s)
+	var bsVec = mat.NewVecDense(n, nil)
+	bsVec.MulVec(b, mat.NewVecDense(n, s))
+	var bs = bsVec.RawVector().Data
+	var sBs, sy = floats.Dot(s, bs), floats.Dot(s, y)
+	if sBs <= 0 || !isFinite(y) {
+		return
+	}
+	var r = y
+	if sy < 0.2*sBs {
+		var mix = 0.8 * sBs / (sBs - sy)
+		r = make([]float64, n)
+		for i := range r {
+			r[i] = mix*y[i] + (1-mix)*bs[i]
+		}
+		sy = floats.Dot(s, r)
+	}
+	b.SymRankOne(b, 1/sy, mat.NewVecDense(n, r))
+	b.SymRankOne(b, -1/sBs, bsVec)
+}
+
+func (o *MultiobjectiveBFGS) move(current *Point) Point {
+	var d, _ = o.quasiNewtonDirection(current)
+	pt, ok := o.linesearch.search(current, d, 1, nil)
+	if !ok {
+		// No step decreases all the objectives, we are (numerically) Pareto-critical
+		o.criticalDetected = true
+		return *current
+	}
+
+	var s = floats.SubTo(make([]float64, len(pt.inputs)), pt.inputs, current.inputs)
+	var before, after = gradientsOf(current), gradientsOf(&pt)
+	for k := range o.hessians {
+		dampedBFGSUpdate(o.hessians[k], s, floats.SubTo(make([]float64, len(s)), after[k], before[k]))
+	}
+	o.current = &pt
+	return pt
+}
+
+func (o *MultiobjectiveBFGS) getCurrent() *Point {
+	return o.current
+}
+
+func (o *MultiobjectiveBFGS) getLineSearcher() LineSearcher {
+	return o.linesearch
+}
+
+func (o *MultiobjectiveBFGS) checkConverged(itNumber uint) bool {
+
+	// Check if we can't find descent steps anymore
+	if o.criticalDetected {
+		fmt.Printf("Let's stop, this point is Pareto-critical.\n")
+		return true
+	}
+
+	// Check if we ran for too long
+	if itNumber > o.maxit {
+		fmt.Printf("Stopping without convergence after %d iterations. =(\n", o.maxit)
+		return true
+	}
+
+	// Check if the quasi-Newton subproblem predicts no decrease anymore
+	_, theta := o.quasiNewtonDirection(o.current)
+	if math.Abs(theta) <= o.tolerance {
+		fmt.Printf("Quasi-Newton decrement %g is below the tolerance threshold (%f), let's stop, we converged!\n", theta, o.tolerance)
+		return true
+	}
+
+	return false
+}
+
+// LimitedMemoryBFGS : A multiobjective L-BFGS method for problems with many variables. All the
+// objectives share one approximation H of the inverse Hessian, defined by the last pairs of
+// steps s and changes y of the gradient of the Lagrangian sum_k lambda_k f_k (lambda being the
+// multipliers of the last direction), and applied with the two-loop recursion. The direction is
+// d = -H sum_k lambda_k g_k, the multipliers minimizing |sum_k lambda_k g_k|_H over the simplex
+// (see simplexQP): it is the Newton direction of the shared model, in O(memory * n * m²).
+type LimitedMemoryBFGS struct {
+	current          *Point       // starting point
+	tolerance        float64      // min |theta(x)| to continue iterating
+	maxit            uint         // max number of iterations before halt
+	memory           int          // number of (s, y) pairs kept
+	linesearch       LineSearcher // chooses the step, starting from the full quasi-Newton step
+	criticalDetected bool         // if no step decreases the objectives anymore
+	steps            [][]float64  // the last s, oldest first
+	changes          [][]float64  // the last y, oldest first
+	direction        []float64    // quasi-Newton direction at directionAt
+	theta            float64      // value of the subproblem at directionAt
+	lambda           []float64    // multipliers of the direction at directionAt
+	directionAt      *Point       // the point where the direction was computed
+}
+
+// newLimitedMemoryBFGS Creates a LimitedMemoryBFGS optimizer keeping the given number of pairs (usually 5 to 20)
+func newLimitedMemoryBFGS(start *Point, tolerance float64, maxit uint, memory int) *LimitedMemoryBFGS {
+	return &LimitedMemoryBFGS{
+		current:    start,
+		tolerance:  tolerance,
+		maxit:      maxit,
+		memory:     memory,
+		linesearch: newArmijoBacktracking(),
+	}
+}
+
+// inverseHessianProduct H v, with the two-loop recursion, H0 being the identity
+// scaled by s^T y / y^T y of the last pair.
+func (o *LimitedMemoryBFGS) inverseHessianProduct(v []float64) []float64 {
+	var q = append([]float64{}, v...)
+	var alpha = make([]float64, len(o.steps))
+	for i := len(o.steps) - 1; i >= 0; i-- {
+		alpha[i] = floats.Dot(o.steps[i], q) / floats.Dot(o.changes[i], o.steps[i])
+		floats.AddScaled(q, -alpha[i], o.changes[i])
+	}
+	if last := len(o.steps) - 1; last >= 0 {
+		floats.Scale(floats.Dot(o.steps[last], o.changes[last])/floats.Dot(o.changes[last], o.changes[last]), q)
+	}
+	for i := range o.steps {
+		var beta = floats.Dot(o.changes[i], q) / floats.Dot(o.changes[i], o.steps[i])
+		floats.AddScaled(q, alpha[i]-beta, o.steps[i])
+	}
+	return q
+}
+
+// quasiNewtonDirection Computes (and remembers) the direction, theta = -1/2 d^T H^-1 d and the multipliers at a point
+func (o *LimitedMemoryBFGS) quasiNewtonDirection(pt *Point) ([]float64, float64) {
+	if o.directionAt != pt {
+		var grads = gradientsOf(pt)
+		var m = len(grads)
+		var hg = make([][]float64, m)
+		for k := range grads {
+			hg[k] = o.inverseHessianProduct(grads[k])
+		}
+		var q = mat.NewSymDense(m, nil)
+		for a := 0; a < m; a++ {
+			for b := a; b < m; b++ {
+				q.SetSym(a, b, floats.Dot(grads[a], hg[b]))
+			}
+		}
+		o.lambda = simplexQP(q, make([]float64, m))
+		o.direction = make([]float64, pt.Problem.nVars)
+		var combined = make([]float64, pt.Problem.nVars)
+		for k := range grads {
+			floats.AddScaled(o.direction, -o.lambda[k], hg[k])
+			floats.AddScaled(combined, o.lambda[k], grads[k])
+		}
+		o.theta = 0.5 * floats.Dot(combined, o.direction)
+		o.directionAt = pt
+	}
+	return o.direction, o.theta
+}
+
+func (o *LimitedMemoryBFGS) move(current *Point) Point {
+	var d, _ = o.quasiNewtonDirection(current)
+	var lambda = o.lambda
+	pt, ok := o.linesearch.search(current, d, 1, nil)
+	if !ok {
+		// No step decreases all the objectives, we are (numerically) Pareto-critical
+		o.criticalDetected = true
+		return *current
+	}
+
+	// New pair, skipped when it has no positive curvature
+	var s = floats.SubTo(make([]float64, len(pt.inputs)), pt.inputs, current.inputs)
+	var y = make([]float64, len(s))
+	var before, after = gradientsOf(current), gradientsOf(&pt)
+	for k := range lambda {
+		floats.AddScaled(y, lambda[k], after[k])
+		floats.AddScaled(y, -lambda[k], before[k])
+	}
+	if sy := floats.Dot(s, y); sy > 1e-10*floats.Norm(s, 2)*floats.Norm(y, 2) && isFinite(y) {
+		o.steps, o.changes = append(o.steps, s), append(o.changes, y)
+		if len(o.steps) > o.memory {
+			o.steps, o.changes = o.steps[1:], o.changes[1:]
+		}
+	}
+	o.current = &pt
+	return pt
+}
+
+func (o *LimitedMemoryBFGS) getCurrent() *Point {
+	return o.current
+}
+
+func (o *LimitedMemoryBFGS) getLineSearcher() LineSearcher {
+	return o.linesearch
+}
+
+func (o *LimitedMemoryBFGS) checkConverged(itNumber uint) bool {
+
+	// Check if we can't find descent steps anymore
+	if o.criticalDetected {
+		fmt.Printf("Let's stop, this point is Pareto-critical.\n")
+		return true
+	}
+
+	// Check if we ran for too long
+	if itNumber > o.maxit {
+		fmt.Printf("Stopping without convergence after %d iterations. =(\n", o.maxit)
+		return true
+	}
+
+	// Check if the quasi-Newton subproblem predicts no decrease anymore
+	_, theta := o.quasiNewtonDirection(o.current)
+	if math.Abs(theta) <= o.tolerance {
+		fmt.Printf("Quasi-Newton decrement %g is below the tolerance threshold (%f), let's stop, we converged!\n", theta, o.tolerance)
+		return true
+	}
+
+	return false
+}
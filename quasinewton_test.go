@@ -0,0 +1,102 @@
+package main
+
+import (
+	"testing"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestDampedBFGSUpdateNegativeCurvature(t *testing.T) {
+	var cases = []struct {
+		name string
+		s, y []float64
+	}{
+		{"s^T y < 0", []float64{1, 0, 0}, []float64{-1, 0.5, 0}},
+		{"s^T y = 0", []float64{1, 1, 0}, []float64{1, -1, 2}},
+		{"small s^T y", []float64{0, 2, 1}, []float64{0.1, 0.05, -0.05}},
+	}
+	for _, c := range cases {
+		var b = mat.NewSymDense(3, []float64{2, 1, 0, 1, 2, 0, 0, 0, 1})
+		dampedBFGSUpdate(b, c.s, c.y)
+		var chol mat.Cholesky
+		if !chol.Factorize(b) {
+			t.Errorf("%s: B %v is not positive definite", c.name, mat.Formatted(b))
+			continue
+		}
+		// the damped secant equation B s = r holds, with s^T r = 0.2 s^T B0 s
+		var bs = mat.NewVecDense(3, nil)
+		bs.MulVec(b, mat.NewVecDense(3, c.s))
+		var sBs = floats.Dot(c.s, bs.RawVector().Data)
+		var s0 = mat.NewVecDense(3, c.s)
+		var b0 = mat.NewSymDense(3, []float64{2, 1, 0, 1, 2, 0, 0, 0, 1})
+		var want = 0.2 * mat.Inner(s0, b0, s0)
+		if !closeTo(sBs, want, 1e-12) {
+			t.Errorf("%s: s^T B s %g, want %g", c.name, sBs, want)
+		}
+	}
+}
+
+func TestInverseHessianProductMatchesBFGS(t *testing.T) {
+	// on a quadratic of Hessian A, the pairs are y = A s
+	var a = mat.NewSymDense(3, []float64{4, 1, 0, 1, 3, -1, 0, -1, 2})
+	var steps = [][]float64{{1, 0, 0}, {0.5, -1, 0.2}, {0, 0.3, 1}, {-0.2, 0.4, 0.1}}
+	var o = &LimitedMemoryBFGS{}
+	for _, s := range steps {
+		var y = mat.NewVecDense(3, nil)
+		y.MulVec(a, mat.NewVecDense(3, s))
+		o.steps, o.changes = append(o.steps, s), append(o.changes, y.RawVector().Data)
+	}
+
+	// dense inverse update H = (I - rho s y^T) H (I - rho y s^T) + rho s s^T, from the same H0
+	var last = len(steps) - 1
+	var h = mat.NewDense(3, 3, nil)
+	for i := 0; i < 3; i++ {
+		h.Set(i, i, floats.Dot(o.steps[last], o.changes[last])/floats.Dot(o.changes[last], o.changes[last]))
+	}
+	for i := range o.steps {
+		var s, y = mat.NewVecDense(3, o.steps[i]), mat.NewVecDense(3, o.changes[i])
+		var rho = 1 / mat.Dot(s, y)
+		var left = mat.NewDense(3, 3, []float64{1, 0, 0, 0, 1, 0, 0, 0, 1})
+		var sy = mat.NewDense(3, 3, nil)
+		sy.Outer(rho, s, y)
+		left.Sub(left, sy)
+		var next = mat.NewDense(3, 3, nil)
+		next.Product(left, h, left.T())
+		var ss = mat.NewDense(3, 3, nil)
+		ss.Outer(rho, s, s)
+		next.Add(next, ss)
+		h = next
+	}
+
+	for _, v := range [][]float64{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}, {1, -2, 0.5}} {
+		var want = mat.NewVecDense(3, nil)
+		want.MulVec(h, mat.NewVecDense(3, v))
+		var got = o.inverseHessianProduct(v)
+		if !floats.EqualApprox(got, want.RawVector().Data, 1e-12) {
+			t.Errorf("H %v: %v, want %v", v, got, want.RawVector().Data)
+		}
+	}
+}
+
+func TestQuasiNewtonParetoCritical(t *testing.T) {
+	var p = mustProblemFromEquations([]string{"(x-1)**2 + 2*y**2", "(x+1)**2 + (y-2)**2 + x*y"}, "x", "y")
+	var optimizers = []struct {
+		name string
+		new  func(start *Point) Optimizer
+	}{
+		{"MultiobjectiveBFGS", func(start *Point) Optimizer { return newMultiobjectiveBFGS(start, 1e-12, 200) }},
+		{"LimitedMemoryBFGS", func(start *Point) Optimizer { return newLimitedMemoryBFGS(start, 1e-12, 200, 5) }},
+	}
+	for _, c := range optimizers {
+		for _, x := range [][]float64{{3, -2}, {-4, 5}, {0.5, 0.5}} {
+			var start = p.evaluate(x)
+			var trajectory = descend(c.new(&start))
+			var end = trajectory[len(trajectory)-1]
+			var d, _, _ = minNormDirection(gradientsOf(&end))
+			if norm := floats.Norm(d, 2); !(norm < 1e-5) {
+				t.Errorf("%s from %v: stops at %v, where the steepest common descent direction has norm %g", c.name, x, end.inputs, norm)
+			}
+		}
+	}
+}
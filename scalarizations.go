@@ -0,0 +1,198 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/optimize"
+)
+
+// ##############################################################
+// Scalarizations of a multiobjective Problem into a single-objective
+// gonum optimize.Problem, to use any gonum method on it (see gonum_stuff.go).
+// Their scalarizing functions also build the subproblems of the front sweeps
+// (see scalarProblem in sweeps.go) and of MOEA/D.
+//
+// The gradient of s(f(x)) is J(x)^T c and its Hessian is sum_k c_k H_k(x), c being
+// the gradient of s with respect to f: this is exact for the scalarizing functions
+// which are piecewise linear in the objective values (all but the PBI). Where s is not
+// differentiable (a tie in a max), c is the subgradient of the first active term.
+// The bounds and constraints of the Problem are ignored: gonum only does
+// unconstrained minimization.
+// ##############################################################
+
+// scalarizingFunction Returns s(f) and its (sub)gradient c with respect to f
+type scalarizingFunction func(f []float64) (float64, []float64)
+
+// scalarize Builds the optimize.Problem minimizing s(f(x))
+func scalarize(p *Problem, s scalarizingFunction) optimize.Problem {
+	var n = p.nVars
+	return optimize.Problem{
+		Func: func(x []float64) float64 {
+			value, _ := s(p.values(x))
+			return value
+		},
+		Grad: func(grad, x []float64) {
+			_, c := s(p.values(x))
+			var jac = p.jacobian(x).Data().([]float64)
+			for v := 0; v < n; v++ {
+				grad[v] = 0
+				for k, ck := range c {
+					grad[v] += ck * jac[k*n+v]
+				}
+			}
+		},
+		Hess: func(hess *mat.SymDense, x []float64) {
+			_, c := s(p.values(x))
+			var h = p.hessian(x).Data().([]float64)
+			for a := 0; a < n; a++ {
+				for b := a; b < n; b++ {
+					var hab = 0.0
+					for k, ck := range c {
+						hab += ck * h[k*n*n+a*n+b]
+					}
+					hess.SetSym(a, b, hab)
+				}
+			}
+		},
+	}
+}
+
+// checkScalarizationParameters Panics if a parameter vector has not one value per objective
+func checkScalarizationParameters(p *Problem, vectors ...[]float64) {
+	for _, v := range vectors {
+		if len(v) != p.nDims {
+			panic(fmt.Sprintf("scalarization: %d parameters given for %d objectives", len(v), p.nDims))
+		}
+	}
+}
+
+// weightedSum Minimizes sum_k w_k f_k(x).
+// Only finds the convex parts of the Pareto front.
+func weightedSum(p *Problem, weights []float64) optimize.Problem {
+	checkScalarizationParameters(p, weights)
+	return scalarize(p, weightedSumFunction(weights))
+}
+
+// weightedSumFunction The scalarizing function of weightedSum
+func weightedSumFunction(weights []float64) scalarizingFunction {
+	return func(f []float64) (float64, []float64) {
+		return floats.Dot(weights, f), weights
+	}
+}
+
+// chebyshevTerm Returns the index of the largest w_k |f_k - z_k|, and its value
+func chebyshevTerm(f, weights, z []float64) (int, float64) {
+	var active, largest = 0, math.Inf(-1)
+	for k := range f {
+		if t := weights[k] * math.Abs(f[k]-z[k]); t > largest {
+			active, largest = k, t
+		}
+	}
+	return active, largest
+}
+
+// sign The derivative of |t|, 0 at 0
+func sign(t float64) float64 {
+	switch {
+	case t > 0:
+		return 1
+	case t < 0:
+		return -1
+	}
+	return 0
+}
+
+// tchebycheff Minimizes max_k w_k |f_k(x) - z_k|, z being the ideal (or utopian) point.
+// Every Pareto optimal point is the solution for some weights, but the solutions can be
+// weakly Pareto optimal only.
+func tchebycheff(p *Problem, weights, ideal []float64) optimize.Problem {
+	return augmentedTchebycheff(p, weights, ideal, 0)
+}
+
+// augmentedTchebycheff Minimizes max_k w_k |f_k(x) - z_k| + rho sum_k |f_k(x) - z_k|.
+// The small augmentation rho > 0 (typically 1e-3) removes the weakly Pareto optimal solutions.
+func augmentedTchebycheff(p *Problem, weights, ideal []float64, rho float64) optimize.Problem {
+	checkScalarizationParameters(p, weights, ideal)
+	return scalarize(p, augmentedTchebycheffFunction(weights, ideal, rho))
+}
+
+// augmentedTchebycheffFunction The scalarizing function of augmentedTchebycheff
+func augmentedTchebycheffFunction(weights, ideal []float64, rho float64) scalarizingFunction {
+	return func(f []float64) (float64, []float64) {
+		var active, value = chebyshevTerm(f, weights, ideal)
+		var c = make([]float64, len(f))
+		for k := range f {
+			value += rho * math.Abs(f[k]-ideal[k])
+			c[k] = rho * sign(f[k]-ideal[k])
+		}
+		c[active] += weights[active] * sign(f[active]-ideal[active])
+		return value, c
+	}
+}
+
+// penaltyBoundaryIntersection Minimizes d1 + theta d2, d1 being the distance from the ideal
+// point z along the direction of the weights, and d2 the distance to this line:
+//
+//	d1 = (f(x) - z)^T w / |w|,  d2 = |f(x) - z - d1 w / |w||
+//
+// The penalty theta (typically 5) pulls the solution on the line, which also reaches the
+// non-convex parts of the Pareto front. Its Hessian neglects the curvature of d2.
+func penaltyBoundaryIntersection(p *Problem, weights, ideal []float64, theta float64) optimize.Problem {
+	checkScalarizationParameters(p, weights, ideal)
+	return scalarize(p, penaltyBoundaryIntersectionFunction(weights, ideal, theta))
+}
+
+// penaltyBoundaryIntersectionFunction The scalarizing function of penaltyBoundaryIntersection
+func penaltyBoundaryIntersectionFunction(weights, ideal []float64, theta float64) scalarizingFunction {
+	var norm = floats.Norm(weights, 2)
+	return func(f []float64) (float64, []float64) {
+		var direction = make([]float64, len(f)) // unit vector of the weights
+		floats.ScaleTo(direction, 1/norm, weights)
+		var shifted = floats.SubTo(make([]float64, len(f)), f, ideal)
+		var d1 = floats.Dot(shifted, direction)
+		var residual = floats.AddScaledTo(make([]float64, len(f)), shifted, -d1, direction)
+		var d2 = floats.Norm(residual, 2)
+
+		// gradient: w/|w| + theta (f - z - d1 w/|w|) / d2
+		var c = direction
+		if d2 > 0 {
+			floats.AddScaled(c, theta/d2, residual)
+		}
+		return d1 + theta*d2, c
+	}
+}
+
+// achievementScalarizing Minimizes Wierzbicki's achievement scalarizing function
+//
+//	max_k w_k (f_k(x) - z_k) + rho sum_k (f_k(x) - z_k)
+//
+// z being a reference point given by the decision maker, attainable or not.
+// The solution is the Pareto optimal point the closest to z along the direction
+// of the weights (usually 1 / the range of each objective).
+func achievementScalarizing(p *Problem, weights, reference []float64, rho float64) optimize.Problem {
+	checkScalarizationParameters(p, weights, reference)
+	return scalarize(p, achievementScalarizingFunction(weights, reference, rho))
+}
+
+// achievementScalarizingFunction The scalarizing function of achievementScalarizing
+func achievementScalarizingFunction(weights, reference []float64, rho float64) scalarizingFunction {
+	return func(f []float64) (float64, []float64) {
+		var active, largest = 0, math.Inf(-1)
+		var sum = 0.0
+		for k := range f {
+			if t := weights[k] * (f[k] - reference[k]); t > largest {
+				active, largest = k, t
+			}
+			sum += f[k] - reference[k]
+		}
+		var c = make([]float64, len(f))
+		for k := range c {
+			c[k] = rho
+		}
+		c[active] += weights[active]
+		return largest + rho*sum, c
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"testing"
+
+	"gonum.org/v1/gonum/diff/fd"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/optimize"
+)
+
+func TestScalarizationDerivatives(t *testing.T) {
+	var p = mustProblemFromEquations([]string{"(x-1)**2 + x*y**2", "exp(x/2) + (y+1)**2"}, "x", "y")
+	var weights, ideal = []float64{0.3, 0.7}, []float64{-1, -1}
+	var cases = []struct {
+		name    string
+		problem optimize.Problem
+		hessian bool // if the Hessian is exact
+	}{
+		{"weighted sum", weightedSum(&p, weights), true},
+		{"Tchebycheff", tchebycheff(&p, weights, ideal), true},
+		{"augmented Tchebycheff", augmentedTchebycheff(&p, weights, ideal, 0.01), true},
+		// the Hessian of the PBI neglects the curvature of d2, which theta = 0 removes
+		{"PBI", penaltyBoundaryIntersection(&p, weights, ideal, 5), false},
+		{"PBI without penalty", penaltyBoundaryIntersection(&p, weights, ideal, 0), true},
+		{"achievement scalarizing", achievementScalarizing(&p, weights, []float64{0.5, 2}, 0.01), true},
+	}
+	for _, c := range cases {
+		for _, x := range [][]float64{{0.5, 0.3}, {-0.7, 1.2}, {1.5, -0.4}, {2, 2}} {
+			var grad = make([]float64, 2)
+			c.problem.Grad(grad, x)
+			var numerical = fd.Gradient(nil, c.problem.Func, x, &fd.Settings{Formula: fd.Central})
+			if !floats.EqualApprox(grad, numerical, 1e-6) {
+				t.Errorf("%s at %v: gradient %v, finite differences %v", c.name, x, grad, numerical)
+			}
+			if !c.hessian {
+				continue
+			}
+			var hess = mat.NewSymDense(2, nil)
+			c.problem.Hess(hess, x)
+			var jacobian = mat.NewDense(2, 2, nil)
+			fd.Jacobian(jacobian, func(y, x []float64) { c.problem.Grad(y, x) }, x, &fd.JacobianSettings{Formula: fd.Central})
+			if !mat.EqualApprox(hess, jacobian, 1e-6) {
+				t.Errorf("%s at %v: Hessian %v, finite differences %v", c.name, x, mat.Formatted(hess), mat.Formatted(jacobian))
+			}
+		}
+	}
+}
@@ -3,18 +3,18 @@ package main
 import (
 	"fmt"
 	"log"
+	"math"
 
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
 	"gonum.org/v1/gonum/optimize"
 )
 
 func solve(p *optimize.Problem, current []float64, settings *optimize.Settings, method optimize.Method) {
-	result, err := optimize.Minimize(*p, current, settings, method)
+	result, err := minimize(p, current, settings, method)
 	if err != nil {
 		log.Fatal(err)
 	}
-	if err = result.Status.Err(); err != nil {
-		log.Fatal(err)
-	}
 
 	s := result.Status
 	stats := result.Stats
@@ -25,3 +25,139 @@ func solve(p *optimize.Problem, current []float64, settings *optimize.Settings,
 	fmt.Printf("Reached F(%0.4g) = %0.4g in %v\n", result.X, result.F, stats.Runtime)
 	fmt.Printf("Finished in %d iterations\n", stats.MajorIterations)
 }
+
+// minimize Runs a gonum method, and returns its result with the error which stopped it, if any.
+// The result is nil only if the method could not start.
+func minimize(p *optimize.Problem, current []float64, settings *optimize.Settings, method optimize.Method) (*optimize.Result, error) {
+	result, err := optimize.Minimize(*p, current, settings, method)
+	if err != nil {
+		return result, err
+	}
+	return result, result.Status.Err()
+}
+
+// GonumOptimizer : Runs a gonum method on the first objective of a problem, in a single move,
+// so that gonum can solve the subproblems of our methods (see sweeps.go and constraints.go).
+// gonum has no bounds: it minimizes boxedObjective, which only evaluates the problem in its box.
+type GonumOptimizer struct {
+	current  *Point
+	method   optimize.Method    // a fresh method for every GonumOptimizer, gonum methods keep a state
+	settings *optimize.Settings // nil for the gonum defaults
+	done     bool
+	err      error // the error which stopped the last run without progress, if any
+}
+
+func newGonumOptimizer(start *Point, method optimize.Method, settings *optimize.Settings) *GonumOptimizer {
+	return &GonumOptimizer{current: start, method: method, settings: settings}
+}
+
+// boxedObjective The first objective of p for gonum, evaluated at the projection of x in the box
+// of p, plus half the squared distance of x to the box. It is continuous, its minima are those of
+// the bounded problem, and the problem is never evaluated outside of its domain. On the variables
+// out of the box, or on a bound the gradient points out of, the gradient and the Hessian are those
+// of the distance term (the gradient is null on the bound): the minima on the bounds are stationary.
+func boxedObjective(p *Problem) optimize.Problem {
+	var n = p.nVars
+	var first = make([]float64, p.nDims)
+	first[0] = 1
+	var problem = scalarize(p, func(f []float64) (float64, []float64) { return f[0], first })
+	if !p.isBounded() {
+		return problem
+	}
+	// the variables given by the distance term, with their gradient g
+	var blocked = func(x, projected []float64, i int, g float64) bool {
+		return x[i] != projected[i] || (x[i] == p.lower[i] && g > 0) || (x[i] == p.upper[i] && g < 0)
+	}
+	return optimize.Problem{
+		Func: func(x []float64) float64 {
+			var projected = p.project(x)
+			var distance = floats.Distance(x, projected, 2)
+			return problem.Func(projected) + 0.5*distance*distance
+		},
+		Grad: func(grad, x []float64) {
+			var projected = p.project(x)
+			problem.Grad(grad, projected)
+			for i := range x {
+				if blocked(x, projected, i, grad[i]) {
+					grad[i] = x[i] - projected[i]
+				}
+			}
+		},
+		Hess: func(hess *mat.SymDense, x []float64) {
+			var projected = p.project(x)
+			var grad = make([]float64, n)
+			problem.Grad(grad, projected)
+			problem.Hess(hess, projected)
+			for i := 0; i < n; i++ {
+				if !blocked(x, projected, i, grad[i]) {
+					continue
+				}
+				for j := 0; j < n; j++ {
+					hess.SetSym(i, j, 0)
+				}
+				hess.SetSym(i, i, 1)
+			}
+		},
+	}
+}
+
+// move Runs the gonum method from the current point. A run which stops on an error (a failed line
+// search, usually) is kept if it made progress or ended at a stationary point, as our methods stop
+// when their line search fails. Otherwise the current point is kept, the error is logged and kept
+// in err, and the optimizer stops: one failed subproblem does not stop a sweep.
+func (o *GonumOptimizer) move(current *Point) Point {
+	var pb = current.Problem
+	var problem = boxedObjective(pb)
+	result, err := minimize(&problem, gonumStart(pb, current.inputs), o.settings, o.method)
+	o.done = true
+	if result != nil {
+		var pt = pb.evaluate(pb.project(result.X))
+		if err == nil || imagesOf(&pt)[0] < imagesOf(current)[0] || projectedGradientNorm(&pt, 0) <= gonumStationarity {
+			o.current = &pt
+			return pt
+		}
+	}
+	log.Printf("gonum failed from %v: %v", current.inputs, err)
+	o.err = err
+	o.current = current
+	return *current
+}
+
+// gonumStart The starting point of gonum from x: gonum cannot start where the gradient is not
+// finite (where f1 = 0 for ZDT1, say), so x is then moved toward the center of the box, by growing
+// fractions of the distance, until the gradient of the first objective is finite.
+func gonumStart(p *Problem, x []float64) []float64 {
+	var finite = func(y []float64) bool {
+		var grad = p.jacobian(y).Data().([]float64)[:p.nVars]
+		return !math.IsNaN(floats.Sum(grad)) && !math.IsInf(floats.Sum(grad), 0)
+	}
+	if finite(x) || !p.isBounded() {
+		return x
+	}
+	for t := 1e-8; t < 1; t *= 10 {
+		var y = make([]float64, len(x))
+		for i := range x {
+			var center = x[i]
+			if !math.IsInf(p.lower[i], 0) && !math.IsInf(p.upper[i], 0) {
+				center = (p.lower[i] + p.upper[i]) / 2
+			}
+			y[i] = x[i] + t*(center-x[i])
+		}
+		if finite(y) {
+			return y
+		}
+	}
+	return x
+}
+
+// gonumStationarity The projected gradient norm below which GonumOptimizer accepts a run which
+// stopped on an error without progress
+const gonumStationarity = 1e-6
+
+func (o *GonumOptimizer) getCurrent() *Point {
+	return o.current
+}
+
+func (o *GonumOptimizer) checkConverged(itNumber uint) bool {
+	return o.done
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+// ##############################################################
+// Adaptive step sizes, as LineSearchers: the step is computed from the
+// previous iterations instead of starting every search from the stepLength
+// of the optimizer (which is then only used for the very first trial).
+// They work with any descent direction, and with several objectives: with
+// SteepestDescent, every objective must decrease.
+//
+// References:
+//  - Barzilai, J., Borwein, J.M.: Two-Point Step Size Gradient Methods.
+//    IMA Journal of Numerical Analysis 8(1) (1988), 141-148
+//  - Grippo, L., Lampariello, F., Lucidi, S.: A Nonmonotone Line Search
+//    Technique for Newton's Method. SIAM Journal on Numerical Analysis 23(4) (1986), 707-716
+//  - Raydan, M.: The Barzilai and Borwein Gradient Method for the Large Scale
+//    Unconstrained Minimization Problem. SIAM Journal on Optimization 7(1) (1997), 26-33
+//  - Mita, K., Fukuda, E.H., Yamashita, N.: Nonmonotone line searches for
+//    unconstrained multiobjective optimization problems. Journal of Global
+//    Optimization 75 (2019), 63-90
+//  - Beck, A., Teboulle, M.: A Fast Iterative Shrinkage-Thresholding Algorithm
+//    for Linear Inverse Problems. SIAM Journal on Imaging Sciences 2(1) (2009), 183-202
+//  - Tanabe, H., Fukuda, E.H., Yamashita, N.: Proximal gradient methods for
+//    multiobjective optimization and their applications. Computational
+//    Optimization and Applications 72 (2019), 339-361
+// ##############################################################
+
+// BarzilaiBorwein : Spectral steps, with a nonmonotone safeguard. With s = x_k - x_k-1 and
+// y = d_k-1 - d_k the change of the direction (the change of the gradient for a single
+// objective, of the combination of the gradients for the steepest direction), the first trial
+// step is s^T s / s^T y (long step) or s^T y / y^T y (short step), clamped to [minStep, maxStep]
+// (the previous accepted step, clamped too, if s^T y <= 0: without positive curvature, no spectral step).
+// The step is accepted if every objective is below its largest value over the last memory
+// iterations, minus the Armijo decrease; otherwise it is multiplied by contraction.
+// The objectives may then increase at some iterations, which the spectral steps need to be fast.
+type BarzilaiBorwein struct {
+	short             bool        // use the short step s^T y / y^T y
+	c1                float64     // fraction of the predicted decrease which must be achieved
+	contraction       float64     // factor applied to the step after a rejected trial
+	memory            int         // number of previous iterations in the reference values (1 for a monotone search)
+	minStep, maxStep  float64     // safeguards of the spectral step
+	maxTrials         int         // number of trials before giving up
+	count             int         // evaluations so far
+	previous          []float64   // x_k-1
+	previousDirection []float64   // d_k-1
+	accepted          []float64   // x_k, the point returned by the last search
+	acceptedStep      float64     // the step which gave x_k
+	history           [][]float64 // values of the objectives at the last accepted points, oldest first
+}
+
+// newBarzilaiBorwein Creates a spectral line search with the long or short step, and the usual parameters
+func newBarzilaiBorwein(short bool) *BarzilaiBorwein {
+	return &BarzilaiBorwein{short: short, c1: 1e-4, contraction: 0.5, memory: 10, minStep: 1e-10, maxStep: 1e10, maxTrials: 50}
+}
+
+// spectralStep The safeguarded Barzilai-Borwein step from the previous iteration to x, with the direction d at x
+func (ls *BarzilaiBorwein) spectralStep(x, d []float64) float64 {
+	var s = floats.SubTo(make([]float64, len(x)), x, ls.previous)
+	var y = floats.SubTo(make([]float64, len(d)), ls.previousDirection, d)
+	var sy = floats.Dot(s, y)
+	var t = ls.acceptedStep // no positive curvature along s
+	if sy > 0 && ls.short {
+		t = sy / floats.Dot(y, y)
+	} else if sy > 0 {
+		t = floats.Dot(s, s) / sy
+	}
+	return math.Max(ls.minStep, math.Min(ls.maxStep, t))
+}
+
+// reference The largest value of every objective over the history
+func (ls *BarzilaiBorwein) reference() []float64 {
+	var worst = append([]float64{}, ls.history[0]...)
+	for _, values := range ls.history[1:] {
+		for k, v := range values {
+			worst[k] = math.Max(worst[k], v)
+		}
+	}
+	return worst
+}
+
+func (ls *BarzilaiBorwein) search(current *Point, direction []float64, initialStep float64, objectives []int) (Point, bool) {
+	objectives = searchedObjectives(current, objectives)
+	var t = initialStep
+	if ls.accepted == nil || !floats.Equal(ls.accepted, current.inputs) {
+		// first search, or the optimizer did not move to our last point: start a new history
+		ls.history = [][]float64{append([]float64{}, imagesOf(current)...)}
+	} else {
+		t = ls.spectralStep(current.inputs, direction)
+	}
+
+	var reference = ls.reference()
+	for trial := 0; trial < ls.maxTrials; trial++ {
+		var pt = trialPoint(current, direction, t)
+		ls.count++
+		if decreaseFrom(reference, current, &pt, ls.c1, objectives) {
+			ls.previous, ls.previousDirection = append([]float64{}, current.inputs...), append([]float64{}, direction...)
+			ls.accepted, ls.acceptedStep = append([]float64{}, pt.inputs...), t
+			ls.history = append(ls.history, append([]float64{}, imagesOf(&pt)...))
+			if len(ls.history) > ls.memory {
+				ls.history = ls.history[len(ls.history)-ls.memory:]
+			}
+			return pt, true
+		}
+		t *= ls.contraction
+	}
+	return *current, false
+}
+
+func (ls *BarzilaiBorwein) evaluations() int {
+	return ls.count
+}
+
+// LipschitzBacktracking : Steps 1/L, L being an estimate of the largest Lipschitz constant of
+// the gradients of the objectives. L is multiplied by increase until the upper bound of the
+// descent lemma holds for every objective at the (projected) displacement s:
+// f_k(x + s) <= f_k(x) + grad f_k(x)^T s + L/2 |s|², and multiplied by decrease after every
+// accepted step, so that it follows the local curvature. The first estimate is 1/initialStep.
+type LipschitzBacktracking struct {
+	lipschitz float64 // current estimate L, 0 before the first search
+	increase  float64 // factor applied to L after a rejected trial
+	decrease  float64 // factor applied to L after an accepted step
+	maxTrials int     // number of trials before giving up
+	count     int     // evaluations so far
+}
+
+// newLipschitzBacktracking Creates a Lipschitz constant estimation with the usual parameters
+func newLipschitzBacktracking() *LipschitzBacktracking {
+	return &LipschitzBacktracking{increase: 2, decrease: 0.5, maxTrials: 50}
+}
+
+// descentLemma Tells if the quadratic upper bound with the constant L holds at the trial point for all
+// the objectives. The trial is also rejected if the objectives are not differentiable there, or if
+// the point does not move.
+func descentLemma(current, trial *Point, lipschitz float64, objectives []int) bool {
+	if !isFinite(trial.gradient.Data().([]float64)) || floats.Equal(trial.inputs, current.inputs) {
+		return false
+	}
+	var s = floats.SubTo(make([]float64, len(trial.inputs)), trial.inputs, current.inputs)
+	var grads = gradientsOf(current)
+	var before, after = imagesOf(current), imagesOf(trial)
+	var curvature = 0.5 * lipschitz * floats.Dot(s, s)
+	for _, k := range objectives {
+		if !(after[k] <= before[k]+floats.Dot(grads[k], s)+curvature) {
+			return false
+		}
+	}
+	return true
+}
+
+func (ls *LipschitzBacktracking) search(current *Point, direction []float64, initialStep float64, objectives []int) (Point, bool) {
+	objectives = searchedObjectives(current, objectives)
+	if ls.lipschitz <= 0 {
+		ls.lipschitz = 1
+		if initialStep > 0 {
+			ls.lipschitz = 1 / initialStep
+		}
+	}
+	for trial := 0; trial < ls.maxTrials; trial++ {
+		var pt = trialPoint(current, direction, 1/ls.lipschitz)
+		ls.count++
+		if descentLemma(current, &pt, ls.lipschitz, objectives) {
+			ls.lipschitz *= ls.decrease
+			return pt, true
+		}
+		ls.lipschitz *= ls.increase
+	}
+	return *current, false
+}
+
+func (ls *LipschitzBacktracking) evaluations() int {
+	return ls.count
+}
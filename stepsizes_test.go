@@ -0,0 +1,87 @@
+package main
+
+import (
+	"testing"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+func TestSpectralStep(t *testing.T) {
+	var cases = []struct {
+		name                 string
+		short                bool
+		previousDirection, d []float64
+		acceptedStep, want   float64
+	}{
+		// s = (1, 1) and y = (2, 1): s^T s / s^T y = 2/3, s^T y / y^T y = 3/5
+		{"long", false, []float64{1, 0}, []float64{-1, -1}, 1, 2. / 3},
+		{"short", true, []float64{1, 0}, []float64{-1, -1}, 1, 3. / 5},
+		// y = 0, then y = -(1, 1): s^T y <= 0, the previous step is kept in [minStep, maxStep]
+		{"s^T y = 0", false, []float64{1, 1}, []float64{1, 1}, 0.3, 0.3},
+		{"s^T y < 0", true, []float64{-1, -1}, []float64{0, 0}, 0.3, 0.3},
+		{"long previous step", false, []float64{1, 1}, []float64{1, 1}, 1e12, 1e10},
+		{"short previous step", false, []float64{-1, -1}, []float64{0, 0}, 0, 1e-10},
+	}
+	for _, c := range cases {
+		var ls = newBarzilaiBorwein(c.short)
+		ls.previous, ls.previousDirection, ls.acceptedStep = []float64{0, 0}, c.previousDirection, c.acceptedStep
+		if got := ls.spectralStep([]float64{1, 1}, c.d); !closeTo(got, c.want, 1e-12) {
+			t.Errorf("%s: step %g, want %g", c.name, got, c.want)
+		}
+	}
+}
+
+func TestBarzilaiBorweinNonmonotone(t *testing.T) {
+	// from x = 1, the step 1.25 along -f'(1) = -2 goes to x = -1.5, where f = 2.25 > f(1)
+	var p = mustProblemFromEquations([]string{"x**2"}, "x")
+	var cases = []struct {
+		name    string
+		history [][]float64
+		want    float64
+	}{
+		{"below the largest recent value", [][]float64{{4}, {1}}, -1.5},
+		{"monotone", [][]float64{{1}}, -0.25},
+	}
+	for _, c := range cases {
+		var current = p.evaluate([]float64{1})
+		var ls = newBarzilaiBorwein(false)
+		// s^T y = 0: the trial step is the previous one
+		ls.previous, ls.previousDirection, ls.acceptedStep = []float64{2}, []float64{-2}, 1.25
+		ls.accepted, ls.history = []float64{1}, c.history
+		pt, ok := ls.search(&current, []float64{-2}, 1, nil)
+		if !ok || !closeTo(pt.inputs[0], c.want, 1e-12) {
+			t.Errorf("%s: accepted %v (%v), want %g", c.name, pt.inputs, ok, c.want)
+		}
+	}
+}
+
+func TestSteepestDescentWithStepSizes(t *testing.T) {
+	var p = mustProblemFromEquations([]string{"(x-1)**2 + (y-1)**2", "(x+1)**2 + 4*(y+1)**2"}, "x", "y")
+	var searches = []struct {
+		name string
+		new  func() LineSearcher
+	}{
+		{"long Barzilai-Borwein", func() LineSearcher { return newBarzilaiBorwein(false) }},
+		{"short Barzilai-Borwein", func() LineSearcher { return newBarzilaiBorwein(true) }},
+		{"Lipschitz backtracking", func() LineSearcher { return newLipschitzBacktracking() }},
+	}
+	for _, c := range searches {
+		for _, x := range [][]float64{{3, -3}, {-2, 4}, {0, 0}} {
+			var start = p.evaluate(x)
+			var trajectory = descend(&SteepestDescent{&start, 1e-6, 1000, false, 1.0, c.new()})
+			var end = trajectory[len(trajectory)-1]
+			if len(trajectory) > 1000 {
+				t.Errorf("%s from %v: no convergence in 1000 iterations", c.name, x)
+			}
+			var d, _, _ = minNormDirection(gradientsOf(&end))
+			if norm := floats.Norm(d, 2); !(norm <= 1e-6) {
+				t.Errorf("%s from %v: stops at %v, where the steepest common descent direction has norm %g", c.name, x, end.inputs, norm)
+			}
+			for k, f := range imagesOf(&end) {
+				if f > imagesOf(&start)[k] {
+					t.Errorf("%s from %v: objective %d increases from %g to %g", c.name, x, k, imagesOf(&start)[k], f)
+				}
+			}
+		}
+	}
+}
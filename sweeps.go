@@ -0,0 +1,222 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/floats"
+	"gorgonia.org/tensor"
+)
+
+// ##############################################################
+// Front sweeps: approximations of the Pareto front obtained by solving
+// a series of single-objective subproblems, for a grid of parameters of a
+// scalarization. Every subproblem starts from the solution of the closest
+// parameters already solved (warm start), and is solved by any Optimizer
+// of a single-objective Problem: ours, or gonum's with GonumOptimizer.
+// The constrained subproblems are solved with the augmented Lagrangian method.
+//
+// Reference: Miettinen, K.: Nonlinear Multiobjective Optimization.
+// Kluwer Academic Publishers (1999)
+// ##############################################################
+
+// scalarProblem The single-objective Problem minimizing s(f(x)), with the bounds and constraints of p.
+// Its derivatives are built as in scalarize.
+func (p *Problem) scalarProblem(s scalarizingFunction) *Problem {
+	var sub = *p
+	var n = p.nVars
+	sub.nDims = 1
+	sub.equations, sub.ad, sub.fComplex = nil, nil, nil
+	sub.f = func(x []float64) *tensor.Dense {
+		value, _ := s(p.values(x))
+		return tensor.New(tensor.WithShape(1, 1), tensor.WithBacking([]float64{value}))
+	}
+	sub.jacobianf = func(x []float64) *tensor.Dense {
+		_, c := s(p.values(x))
+		var jac = p.jacobian(x).Data().([]float64)
+		var grad = make([]float64, n)
+		for k, ck := range c {
+			floats.AddScaled(grad, ck, jac[k*n:(k+1)*n])
+		}
+		return tensor.New(tensor.WithShape(1, n), tensor.WithBacking(grad))
+	}
+	sub.hessianf = func(x []float64) *tensor.Dense {
+		_, c := s(p.values(x))
+		var hess = p.hessian(x).Data().([]float64)
+		var h = make([]float64, n*n)
+		for k, ck := range c {
+			floats.AddScaled(h, ck, hess[k*n*n:(k+1)*n*n])
+		}
+		return tensor.New(tensor.WithShape(1, n, n), tensor.WithBacking(h))
+	}
+	return &sub
+}
+
+// objectiveFunction The scalarizing function selecting the objective j
+func objectiveFunction(j, nDims int) scalarizingFunction {
+	var c = make([]float64, nDims)
+	c[j] = 1
+	return func(f []float64) (float64, []float64) { return f[j], c }
+}
+
+// objectiveBounded Adds to sub, a problem on the variables of p, the constraints f_k(x) <= bounds_k
+// on the objectives of p whose bound is finite, after the constraints of sub.
+func (p *Problem) objectiveBounded(sub *Problem, bounds []float64) *Problem {
+	checkScalarizationParameters(p, bounds)
+	var bounded = []int{}
+	for k, b := range bounds {
+		if !math.IsInf(b, 1) {
+			bounded = append(bounded, k)
+		}
+	}
+	var n, previous = p.nVars, sub.nIneq
+	var g = func(x []float64) *tensor.Dense {
+		var values = []float64{}
+		if previous > 0 {
+			values = append(values, sub.ineq(x).Data().([]float64)...)
+		}
+		var f = p.values(x)
+		for _, k := range bounded {
+			values = append(values, f[k]-bounds[k])
+		}
+		return tensor.New(tensor.WithShape(len(values), 1), tensor.WithBacking(values))
+	}
+	var jacobian = func(x []float64) *tensor.Dense {
+		var rows = []float64{}
+		if previous > 0 {
+			rows = append(rows, constraintJacobian(previous, sub.ineq, sub.ineqJacobian, x).Data().([]float64)...)
+		}
+		var jac = p.jacobian(x).Data().([]float64)
+		for _, k := range bounded {
+			rows = append(rows, jac[k*n:(k+1)*n]...)
+		}
+		return tensor.New(tensor.WithShape(len(rows)/n, n), tensor.WithBacking(rows))
+	}
+	var constrained = sub.withInequalities(previous+len(bounded), g, jacobian)
+	return &constrained
+}
+
+// epsilonConstrained The Problem minimizing f_j(x) subject to f_k(x) <= epsilon_k for the other
+// objectives (epsilon_j is ignored), added to the bounds and constraints of p.
+func (p *Problem) epsilonConstrained(j int, epsilon []float64) *Problem {
+	var bounds = append([]float64{}, epsilon...)
+	bounds[j] = math.Inf(1)
+	return p.objectiveBounded(p.scalarProblem(objectiveFunction(j, p.nDims)), bounds)
+}
+
+// solveSubproblem Minimizes the single-objective problem from x (projected in its box if needed),
+// with the augmented Lagrangian method around the optimizer if it has constraints.
+func solveSubproblem(sub *Problem, x []float64, newOptimizer func(start *Point) Optimizer) []float64 {
+	start, err := sub.startingPoint(x, true)
+	if err != nil {
+		panic(err) // cannot happen, infeasible points are projected
+	}
+	var o Optimizer
+	if sub.isConstrained() {
+		o = newAugmentedLagrangian(&start, newOptimizer, 1e-6, 50)
+	} else {
+		o = newOptimizer(&start)
+	}
+	descend(o)
+	return o.getCurrent().inputs
+}
+
+// sweep Solves the subproblem of every parameter vector in order, starting from the solution of
+// the closest parameters already solved (from start for the first one). solve returns the solution
+// of the subproblem of a parameter vector from a starting point. Returns the solutions, evaluated
+// on p, in the order of the parameters.
+func sweep(p *Problem, start []float64, parameters [][]float64, solve func(parameter, x []float64) []float64) []Point {
+	var solutions = make([]Point, len(parameters))
+	for a, parameter := range parameters {
+		var x = start
+		var closest = math.Inf(1)
+		for b := 0; b < a; b++ {
+			if d := floats.Distance(parameter, parameters[b], 2); d < closest {
+				x, closest = solutions[b].inputs, d
+			}
+		}
+		solutions[a] = p.evaluate(solve(parameter, x))
+	}
+	return solutions
+}
+
+// weightedSumSweep Minimizes the weighted sums of the objectives for all the weight vectors of the
+// simplex lattice with the given number of divisions (divisions+1 points along the front for 2 objectives).
+// Only finds the convex parts of the Pareto front.
+func weightedSumSweep(p *Problem, start []float64, divisions int, newOptimizer func(start *Point) Optimizer) []Point {
+	var weights = simplexLattice(p.nDims, divisions)
+	return sweep(p, start, weights, func(w, x []float64) []float64 {
+		var sub = p.scalarProblem(weightedSumFunction(w))
+		return solveSubproblem(sub, x, newOptimizer)
+	})
+}
+
+// payoffTable Minimizes every objective alone, from the same starting point, then the sum of
+// the others without degrading it: the minima are then not weakly dominated (lexicographic
+// optimization). They give the ideal point, and an estimate of the nadir point.
+func payoffTable(p *Problem, start []float64, newOptimizer func(start *Point) Optimizer) []Point {
+	var minima = make([]Point, p.nDims)
+	for j := range minima {
+		var x = solveSubproblem(p.scalarProblem(objectiveFunction(j, p.nDims)), start, newOptimizer)
+		var best = p.values(x)[j]
+		var others = make([]float64, p.nDims)
+		var bounds = make([]float64, p.nDims)
+		for k := range others {
+			others[k], bounds[k] = 1, math.Inf(1)
+		}
+		others[j], bounds[j] = 0, best+1e-6*math.Max(1, math.Abs(best))
+		var sum = p.scalarProblem(weightedSumFunction(others))
+		minima[j] = p.evaluate(solveSubproblem(p.objectiveBounded(sum, bounds), x, newOptimizer))
+	}
+	return minima
+}
+
+// idealAndNadir The best and worst value of every objective among the individual minima
+func idealAndNadir(minima []Point) ([]float64, []float64) {
+	var ideal = append([]float64{}, imagesOf(&minima[0])...)
+	var nadir = append([]float64{}, ideal...)
+	for a := range minima {
+		for k, fk := range imagesOf(&minima[a]) {
+			ideal[k] = math.Min(ideal[k], fk)
+			nadir[k] = math.Max(nadir[k], fk)
+		}
+	}
+	return ideal, nadir
+}
+
+// epsilonConstraintSweep Minimizes the objective j with upper bounds on the other objectives, for
+// a grid of steps bounds per objective, regularly spaced from its ideal value (excluded) to its nadir
+// value estimated by the payoff table. Finds the non-convex parts of the front too. Where the bounds
+// cannot be met, the solution violates them: filter the result with a ParetoArchive if needed.
+// The sweep goes from the tightest bounds to the loosest, starting from the individual minimum of
+// the first bounded objective: the solutions then follow the front from one end to the other.
+func epsilonConstraintSweep(p *Problem, start []float64, j, steps int, newOptimizer func(start *Point) Optimizer) []Point {
+	if j < 0 || j >= p.nDims || steps < 1 {
+		panic(fmt.Sprintf("epsilon-constraint sweep on objective %d of %d, with %d steps", j, p.nDims, steps))
+	}
+	var minima = payoffTable(p, start, newOptimizer)
+	var ideal, nadir = idealAndNadir(minima)
+
+	var grid = [][]float64{make([]float64, p.nDims)}
+	for k := 0; k < p.nDims; k++ {
+		if k == j {
+			continue
+		}
+		var next = [][]float64{}
+		for _, epsilon := range grid {
+			for s := 1; s <= steps; s++ {
+				var e = append([]float64{}, epsilon...)
+				e[k] = ideal[k] + (nadir[k]-ideal[k])*float64(s)/float64(steps)
+				next = append(next, e)
+			}
+		}
+		grid = next
+	}
+	var first = 0
+	if j == 0 && p.nDims > 1 {
+		first = 1
+	}
+	return sweep(p, minima[first].inputs, grid, func(epsilon, x []float64) []float64 {
+		return solveSubproblem(p.epsilonConstrained(j, epsilon), x, newOptimizer)
+	})
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/optimize"
+)
+
+func TestEpsilonConstraintSweepWithGonum(t *testing.T) {
+	// ZDT1 is not defined for negative variables: gonum must stay in the box
+	var b = newZDT(1, 5)
+	var start = []float64{0.5, 0.5, 0.5, 0.5, 0.5}
+	var solutions = epsilonConstraintSweep(&b.Problem, start, 1, 10, func(s *Point) Optimizer {
+		return newGonumOptimizer(s, &optimize.BFGS{}, nil)
+	})
+	var distinct = map[float64]bool{}
+	for a := range solutions {
+		var f = imagesOf(&solutions[a])
+		if math.Abs(f[1]-(1-math.Sqrt(f[0]))) > 1e-4 {
+			t.Errorf("solution %d (%g) is not on the front", a, f)
+		}
+		distinct[math.Round(f[0]*1e4)] = true
+	}
+	if len(distinct) != len(solutions) {
+		t.Errorf("%d distinct solutions among %d", len(distinct), len(solutions))
+	}
+}
+
+func TestSweepsWithGonumFailures(t *testing.T) {
+	// gonum's line search fails on some subproblems of ZDT3 and ZDT6: the sweeps go on
+	var bfgs = func(s *Point) Optimizer { return newGonumOptimizer(s, &optimize.BFGS{}, nil) }
+	var sweeps = []struct {
+		name string
+		run  func(p *Problem, start []float64) []Point
+	}{
+		{"weighted sum", func(p *Problem, start []float64) []Point { return weightedSumSweep(p, start, 10, bfgs) }},
+		{"epsilon-constraint", func(p *Problem, start []float64) []Point { return epsilonConstraintSweep(p, start, 1, 10, bfgs) }},
+		{"NBI", func(p *Problem, start []float64) []Point { return normalBoundaryIntersection(p, start, 10, bfgs) }},
+	}
+	var problems = []struct {
+		number, nVars int
+		start         float64
+	}{
+		{3, 10, 0},
+		{6, 5, 0.2},
+	}
+	for _, c := range problems {
+		var b = newZDT(c.number, c.nVars)
+		var start = make([]float64, c.nVars)
+		for i := range start {
+			start[i] = c.start
+		}
+		for _, s := range sweeps {
+			var solutions = s.run(&b.Problem, start)
+			if len(solutions) == 0 {
+				t.Errorf("%s sweep on %s: no solution", s.name, b.name)
+			}
+			for a := range solutions {
+				if !b.Problem.isFeasible(solutions[a].inputs) {
+					t.Errorf("%s sweep on %s: solution %d %g is out of the box", s.name, b.name, a, solutions[a].inputs)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,415 @@
+package main
+
+import (
+	"math"
+
+	"gorgonia.org/tensor"
+)
+
+// ##############################################################
+// Symbolic differentiation of the parsed equations, to build the
+// Jacobian and Hessian of a Problem without deriving them by hand.
+// ##############################################################
+
+// powiNode A sub-expression raised to a constant integer power,
+// evaluated by repeated squaring rather than with math.Pow.
+type powiNode struct {
+	base exprNode
+	n    int
+}
+
+func (n *powiNode) eval(v []float64) float64 {
+	var b = n.base.eval(v)
+	var e = n.n
+	if e < 0 {
+		b, e = 1/b, -e
+	}
+	var result = 1.0
+	for e > 0 {
+		if e&1 == 1 {
+			result *= b
+		}
+		b *= b
+		e >>= 1
+	}
+	return result
+}
+
+func (n *powiNode) evalComplex(v []complex128) complex128 {
+	var b = n.base.evalComplex(v)
+	var e = n.n
+	if e < 0 {
+		b, e = 1/b, -e
+	}
+	var result complex128 = 1
+	for e > 0 {
+		if e&1 == 1 {
+			result *= b
+		}
+		b *= b
+		e >>= 1
+	}
+	return result
+}
+
+func (n *powiNode) String() string {
+	return "(" + n.base.String() + "**" + (&constNode{float64(n.n)}).String() + ")"
+}
+
+// ##############################################################
+// Smart constructors, which simplify the expression while building it
+// ##############################################################
+
+func isConst(n exprNode, value float64) bool {
+	c, ok := n.(*constNode)
+	return ok && c.value == value
+}
+
+func constValue(n exprNode) (float64, bool) {
+	c, ok := n.(*constNode)
+	if !ok {
+		return 0, false
+	}
+	return c.value, true
+}
+
+func newNeg(a exprNode) exprNode {
+	if c, ok := constValue(a); ok {
+		return &constNode{-c}
+	}
+	if u, ok := a.(*unaryNode); ok {
+		return u.arg
+	}
+	return &unaryNode{a}
+}
+
+func newAdd(a, b exprNode) exprNode {
+	ca, aConst := constValue(a)
+	cb, bConst := constValue(b)
+	switch {
+	case aConst && bConst:
+		return &constNode{ca + cb}
+	case aConst && ca == 0:
+		return b
+	case bConst && cb == 0:
+		return a
+	}
+	if u, ok := b.(*unaryNode); ok { // a + -b = a - b
+		return newSub(a, u.arg)
+	}
+	return &binaryNode{'+', a, b}
+}
+
+func newSub(a, b exprNode) exprNode {
+	ca, aConst := constValue(a)
+	cb, bConst := constValue(b)
+	switch {
+	case aConst && bConst:
+		return &constNode{ca - cb}
+	case aConst && ca == 0:
+		return newNeg(b)
+	case bConst && cb == 0:
+		return a
+	}
+	if u, ok := b.(*unaryNode); ok { // a - -b = a + b
+		return newAdd(a, u.arg)
+	}
+	return &binaryNode{'-', a, b}
+}
+
+func newMul(a, b exprNode) exprNode {
+	ca, aConst := constValue(a)
+	cb, bConst := constValue(b)
+	switch {
+	case aConst && bConst:
+		return &constNode{ca * cb}
+	case (aConst && ca == 0) || (bConst && cb == 0):
+		return &constNode{0}
+	case aConst && ca == 1:
+		return b
+	case bConst && cb == 1:
+		return a
+	case aConst && ca == -1:
+		return newNeg(b)
+	case bConst && cb == -1:
+		return newNeg(a)
+	case bConst: // keep the constants on the left
+		return newMul(b, a)
+	}
+	if u, ok := a.(*unaryNode); ok {
+		return newNeg(newMul(u.arg, b))
+	}
+	if u, ok := b.(*unaryNode); ok {
+		return newNeg(newMul(a, u.arg))
+	}
+	if aConst { // c1*(c2*x) = (c1*c2)*x
+		if m, ok := b.(*binaryNode); ok && m.op == '*' {
+			if c2, ok := constValue(m.left); ok {
+				return newMul(&constNode{ca * c2}, m.right)
+			}
+		}
+	}
+	return &binaryNode{'*', a, b}
+}
+
+func newDiv(a, b exprNode) exprNode {
+	ca, aConst := constValue(a)
+	cb, bConst := constValue(b)
+	switch {
+	case aConst && bConst && cb != 0:
+		return &constNode{ca / cb}
+	case aConst && ca == 0:
+		return &constNode{0}
+	case bConst && cb == 1:
+		return a
+	case bConst && cb != 0: // dividing by a constant is multiplying by its inverse
+		return newMul(&constNode{1 / cb}, a)
+	}
+	return &binaryNode{'/', a, b}
+}
+
+func newPow(a, b exprNode) exprNode {
+	ca, aConst := constValue(a)
+	cb, bConst := constValue(b)
+	switch {
+	case aConst && bConst:
+		return &constNode{math.Pow(ca, cb)}
+	case bConst && cb == 0:
+		return &constNode{1}
+	case bConst && cb == 1:
+		return a
+	case aConst && ca == 1:
+		return &constNode{1}
+	case bConst && cb == math.Trunc(cb) && math.Abs(cb) <= 64:
+		if pi, ok := a.(*powiNode); ok { // (x**n)**m = x**(n*m)
+			return newPowi(pi.base, pi.n*int(cb))
+		}
+		return newPowi(a, int(cb))
+	}
+	return &binaryNode{'^', a, b}
+}
+
+func newPowi(a exprNode, n int) exprNode {
+	switch n {
+	case 0:
+		return &constNode{1}
+	case 1:
+		return a
+	}
+	return &powiNode{a, n}
+}
+
+func newCall(name string, arg exprNode) exprNode {
+	if c, ok := constValue(arg); ok {
+		return &constNode{exprFunctions[name](c)}
+	}
+	return &callNode{name, arg}
+}
+
+// simplify Rebuilds an expression with the smart constructors,
+// folding the constants and removing the neutral elements.
+func simplify(n exprNode) exprNode {
+	switch n := n.(type) {
+	case *unaryNode:
+		return newNeg(simplify(n.arg))
+	case *binaryNode:
+		a, b := simplify(n.left), simplify(n.right)
+		switch n.op {
+		case '+':
+			return newAdd(a, b)
+		case '-':
+			return newSub(a, b)
+		case '*':
+			return newMul(a, b)
+		case '/':
+			return newDiv(a, b)
+		case '^':
+			return newPow(a, b)
+		}
+	case *callNode:
+		return newCall(n.name, simplify(n.arg))
+	case *powiNode:
+		return newPowi(simplify(n.base), n.n)
+	}
+	return n
+}
+
+// ##############################################################
+// Differentiation rules
+// ##############################################################
+
+// derive Returns the (simplified) partial derivative of n with respect
+// to the variable of the given index.
+func derive(n exprNode, index int) exprNode {
+	switch n := n.(type) {
+	case *constNode:
+		return &constNode{0}
+	case *varNode:
+		if n.index == index {
+			return &constNode{1}
+		}
+		return &constNode{0}
+	case *unaryNode:
+		return newNeg(derive(n.arg, index))
+	case *powiNode:
+		// (u**n)' = n * u**(n-1) * u'
+		return newMul(newMul(&constNode{float64(n.n)}, newPowi(n.base, n.n-1)), derive(n.base, index))
+	case *binaryNode:
+		a, b := n.left, n.right
+		da, db := derive(a, index), derive(b, index)
+		switch n.op {
+		case '+':
+			return newAdd(da, db)
+		case '-':
+			return newSub(da, db)
+		case '*':
+			return newAdd(newMul(da, b), newMul(a, db))
+		case '/':
+			return newDiv(newSub(newMul(da, b), newMul(a, db)), newPowi(b, 2))
+		case '^':
+			if c, ok := constValue(b); ok {
+				// (u**c)' = c * u**(c-1) * u'
+				return newMul(newMul(b, newPow(a, &constNode{c - 1})), da)
+			}
+			// (u**v)' = u**v * (v' * log(u) + v * u' / u)
+			return newMul(n, newAdd(newMul(db, newCall("log", a)), newDiv(newMul(b, da), a)))
+		}
+	case *callNode:
+		var u = n.arg
+		var du = derive(u, index)
+		if isConst(du, 0) {
+			return du
+		}
+		var outer exprNode // derivative of the function, at u
+		switch n.name {
+		case "sin":
+			outer = newCall("cos", u)
+		case "cos":
+			outer = newNeg(newCall("sin", u))
+		case "tan":
+			outer = newAdd(&constNode{1}, newPowi(n, 2))
+		case "asin":
+			outer = newDiv(&constNode{1}, newCall("sqrt", newSub(&constNode{1}, newPowi(u, 2))))
+		case "acos":
+			outer = newNeg(newDiv(&constNode{1}, newCall("sqrt", newSub(&constNode{1}, newPowi(u, 2)))))
+		case "atan":
+			outer = newDiv(&constNode{1}, newAdd(&constNode{1}, newPowi(u, 2)))
+		case "sinh":
+			outer = newCall("cosh", u)
+		case "cosh":
+			outer = newCall("sinh", u)
+		case "tanh":
+			outer = newSub(&constNode{1}, newPowi(n, 2))
+		case "exp":
+			outer = n
+		case "log":
+			outer = newDiv(&constNode{1}, u)
+		case "log10":
+			outer = newDiv(&constNode{1 / math.Ln10}, u)
+		case "sqrt":
+			outer = newDiv(&constNode{0.5}, n)
+		case "abs":
+			outer = newDiv(u, n) // the sign of u, undefined in 0
+		}
+		return newMul(outer, du)
+	}
+	panic("cannot differentiate " + n.String())
+}
+
+// ##############################################################
+// Jacobian and Hessian of a list of equations
+// ##############################################################
+
+// symbolicJacobian Returns the partial derivatives dFi/dxj of the equations.
+func symbolicJacobian(nodes []exprNode, nVars int) [][]exprNode {
+	var jac = make([][]exprNode, len(nodes))
+	for i, node := range nodes {
+		jac[i] = make([]exprNode, nVars)
+		for j := 0; j < nVars; j++ {
+			jac[i][j] = derive(node, j)
+		}
+	}
+	return jac
+}
+
+// symbolicHessian Returns the second partial derivatives d2Fi/dxjdxk of the equations,
+// given their Jacobian. Only the upper triangle is derived, the Hessians being symmetric.
+func symbolicHessian(jac [][]exprNode) [][][]exprNode {
+	var hess = make([][][]exprNode, len(jac))
+	for i, row := range jac {
+		var nVars = len(row)
+		hess[i] = make([][]exprNode, nVars)
+		for j := range row {
+			hess[i][j] = make([]exprNode, nVars)
+		}
+		for j := range row {
+			for k := j; k < nVars; k++ {
+				hess[i][j][k] = derive(row[j], k)
+				hess[i][k][j] = hess[i][j][k]
+			}
+		}
+	}
+	return hess
+}
+
+// compileJacobian Turns the symbolic Jacobian into the function R^M -> M(N, M) of a Problem.
+func compileJacobian(jac [][]exprNode) func([]float64) *tensor.Dense {
+	var nDims, nVars = len(jac), len(jac[0])
+	return func(v []float64) *tensor.Dense {
+		var backing = make([]float64, 0, nDims*nVars)
+		for _, row := range jac {
+			for _, node := range row {
+				backing = append(backing, node.eval(v))
+			}
+		}
+		return tensor.New(tensor.WithShape(nDims, nVars), tensor.WithBacking(backing))
+	}
+}
+
+// compileHessian Turns the symbolic Hessians into the function R^M -> T(N, M, M) of a Problem.
+func compileHessian(hess [][][]exprNode) func([]float64) *tensor.Dense {
+	var nDims, nVars = len(hess), len(hess[0])
+	return func(v []float64) *tensor.Dense {
+		var backing = make([]float64, nDims*nVars*nVars)
+		for i, h := range hess {
+			for j := 0; j < nVars; j++ {
+				for k := j; k < nVars; k++ {
+					var value = h[j][k].eval(v)
+					backing[(i*nVars+j)*nVars+k] = value
+					backing[(i*nVars+k)*nVars+j] = value
+				}
+			}
+		}
+		return tensor.New(tensor.WithShape(nDims, nVars, nVars), tensor.WithBacking(backing))
+	}
+}
+
+// newProblemFromEquations Builds a complete Problem from its equations only:
+// the objective function, its Jacobian and its Hessian are all derived from the strings.
+func newProblemFromEquations(equations []string, variables ...string) (Problem, error) {
+	nodes, err := parseEquations(equations, variables)
+	if err != nil {
+		return Problem{}, err
+	}
+	var jac = symbolicJacobian(nodes, len(variables))
+	var hess = symbolicHessian(jac)
+	return Problem{
+		nVars:     len(variables),
+		nDims:     len(equations),
+		f:         compileNodes(nodes),
+		jacobianf: compileJacobian(jac),
+		hessianf:  compileHessian(hess),
+		fComplex:  compileComplexNodes(nodes),
+		equations: &equations,
+	}, nil
+}
+
+// mustProblemFromEquations Like newProblemFromEquations, but panics if an equation
+// cannot be parsed. Meant for the problems declared as global variables.
+func mustProblemFromEquations(equations []string, variables ...string) Problem {
+	pb, err := newProblemFromEquations(equations, variables...)
+	if err != nil {
+		panic(err)
+	}
+	return pb
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// closeTo Tells if got is within a relative tolerance of want
+func closeTo(got, want, tolerance float64) bool {
+	return math.Abs(got-want) <= tolerance*math.Max(1, math.Abs(want))
+}
+
+func TestSymbolicDerivatives(t *testing.T) {
+	// f(x, y) with its gradient and its Hessian, derived by hand
+	var cases = []struct {
+		equation string
+		gradient func(x, y float64) []float64
+		hessian  func(x, y float64) []float64
+	}{
+		{"x**2*y + 3*y",
+			func(x, y float64) []float64 { return []float64{2 * x * y, x*x + 3} },
+			func(x, y float64) []float64 { return []float64{2 * y, 2 * x, 2 * x, 0} }},
+		{"sin(x*y)",
+			func(x, y float64) []float64 { return []float64{y * math.Cos(x*y), x * math.Cos(x*y)} },
+			func(x, y float64) []float64 {
+				var s, c = math.Sin(x * y), math.Cos(x * y)
+				return []float64{-y * y * s, c - x*y*s, c - x*y*s, -x * x * s}
+			}},
+		{"exp(x)/y",
+			func(x, y float64) []float64 { return []float64{math.Exp(x) / y, -math.Exp(x) / (y * y)} },
+			func(x, y float64) []float64 {
+				var e = math.Exp(x)
+				return []float64{e / y, -e / (y * y), -e / (y * y), 2 * e / (y * y * y)}
+			}},
+		{"log(x)*sqrt(y)",
+			func(x, y float64) []float64 { return []float64{math.Sqrt(y) / x, math.Log(x) / (2 * math.Sqrt(y))} },
+			func(x, y float64) []float64 {
+				var r = math.Sqrt(y)
+				return []float64{-r / (x * x), 1 / (2 * x * r), 1 / (2 * x * r), -math.Log(x) / (4 * y * r)}
+			}},
+		{"x**y",
+			func(x, y float64) []float64 { return []float64{y * math.Pow(x, y-1), math.Pow(x, y) * math.Log(x)} },
+			func(x, y float64) []float64 {
+				var l = math.Log(x)
+				var xy = math.Pow(x, y-1)
+				return []float64{y * (y - 1) * math.Pow(x, y-2), xy * (1 + y*l), xy * (1 + y*l), math.Pow(x, y) * l * l}
+			}},
+		{"(x-y)**3",
+			func(x, y float64) []float64 { return []float64{3 * (x - y) * (x - y), -3 * (x - y) * (x - y)} },
+			func(x, y float64) []float64 {
+				var d = 6 * (x - y)
+				return []float64{d, -d, -d, d}
+			}},
+	}
+	var points = [][]float64{{1.3, 0.7}, {0.4, 2.2}}
+	for _, c := range cases {
+		pb, err := newProblemFromEquations([]string{c.equation}, "x", "y")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, v := range points {
+			var jac = pb.jacobian(v).Data().([]float64)
+			for i, want := range c.gradient(v[0], v[1]) {
+				if !closeTo(jac[i], want, 1e-12) {
+					t.Errorf("%q at %g: df/dx%d = %g, want %g", c.equation, v, i+1, jac[i], want)
+				}
+			}
+			var hess = pb.hessian(v).Data().([]float64)
+			for i, want := range c.hessian(v[0], v[1]) {
+				if !closeTo(hess[i], want, 1e-12) {
+					t.Errorf("%q at %g: Hessian entry %d = %g, want %g", c.equation, v, i, hess[i], want)
+				}
+			}
+		}
+	}
+}
+
+func TestSymbolicDerivativesMatchNumerical(t *testing.T) {
+	// All the functions of the parser, in several objectives of 3 variables
+	var equations = []string{
+		"sin(x)*cos(y) + tan(z/4)",
+		"asin(x/2) + acos(y/3) + atan(x*z)",
+		"sinh(x) - cosh(y)*tanh(z)",
+		"exp(-x*y) + log(1+z*z) + log10(2+x**2)",
+		"sqrt(x*x+y*y+z*z) + abs(x-y)",
+		"x**4 - 2*x**3*y + z**-2 + 2**(x*y)",
+	}
+	pb, err := newProblemFromEquations(equations, "x", "y", "z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, x := range [][]float64{{0.3, 0.8, 1.1}, {-0.9, 0.2, -1.7}, {1.1, -1.4, 0.6}} {
+		if report := CheckDerivatives(&pb, x); !report.ok() {
+			t.Errorf("%v", report)
+		}
+	}
+}
+
+func TestSimplify(t *testing.T) {
+	var cases = []struct {
+		equation, simplified string
+	}{
+		{"x*1+0", "x"},
+		{"0*sin(x)", "0"},
+		{"2*3", "6"},
+	}
+	for _, c := range cases {
+		node, err := parseEquation(c.equation, []string{"x"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := simplify(node).String(); got != c.simplified {
+			t.Errorf("%q simplified to %q, want %q", c.equation, got, c.simplified)
+		}
+	}
+}
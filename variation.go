@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// ##############################################################
+// Variation operators of the evolutionary algorithms, on real
+// variables restricted to the box of the problem.
+//
+// Reference: Deb, K., Agrawal, R.B.: Simulated Binary Crossover for
+// Continuous Search Space. Complex Systems 9 (1995), 115-148
+// ##############################################################
+
+// checkFiniteBounds Returns an error if some variable of the problem is not bounded on both sides,
+// the evolutionary algorithms sampling and mutating in the box.
+func checkFiniteBounds(p *Problem) error {
+	if !p.isBounded() {
+		return fmt.Errorf("the evolutionary algorithms need bounds on the variables, see withBounds")
+	}
+	for i := range p.lower {
+		if math.IsInf(p.lower[i], 0) || math.IsInf(p.upper[i], 0) {
+			return fmt.Errorf("variable %d has an infinite bound", i+1)
+		}
+	}
+	return nil
+}
+
+// randomInBox A uniformly random point of the box of the problem
+func randomInBox(p *Problem, rng *rand.Rand) []float64 {
+	var x = make([]float64, p.nVars)
+	for i := range x {
+		x[i] = p.lower[i] + rng.Float64()*(p.upper[i]-p.lower[i])
+	}
+	return x
+}
+
+// sbxCrossover The bounded simulated binary crossover of two parents: every variable is
+// recombined with probability 0.5, the children being spread around the parents with
+// a polynomial distribution of index eta (large eta: children close to their parents).
+func sbxCrossover(p *Problem, parent1, parent2 []float64, eta float64, rng *rand.Rand) ([]float64, []float64) {
+	var child1 = append([]float64{}, parent1...)
+	var child2 = append([]float64{}, parent2...)
+	for i := range child1 {
+		if rng.Float64() > 0.5 || math.Abs(parent1[i]-parent2[i]) < 1e-14 {
+			continue
+		}
+		var y1, y2 = math.Min(parent1[i], parent2[i]), math.Max(parent1[i], parent2[i])
+		var lower, upper = p.lower[i], p.upper[i]
+		var u = rng.Float64()
+
+		// spread factor, for the distribution truncated at the bound on each side
+		var betaq = func(beta float64) float64 {
+			var alpha = 2 - math.Pow(beta, -(eta+1))
+			if u <= 1/alpha {
+				return math.Pow(u*alpha, 1/(eta+1))
+			}
+			return math.Pow(1/(2-u*alpha), 1/(eta+1))
+		}
+		var c1 = 0.5 * ((y1 + y2) - betaq(1+2*(y1-lower)/(y2-y1))*(y2-y1))
+		var c2 = 0.5 * ((y1 + y2) + betaq(1+2*(upper-y2)/(y2-y1))*(y2-y1))
+		c1 = math.Min(math.Max(c1, lower), upper)
+		c2 = math.Min(math.Max(c2, lower), upper)
+		if rng.Float64() < 0.5 {
+			c1, c2 = c2, c1
+		}
+		child1[i], child2[i] = c1, c2
+	}
+	return child1, child2
+}
+
+// polynomialMutation Mutates every variable with the given probability, with a polynomial
+// distribution of index eta which stays in the bounds.
+func polynomialMutation(p *Problem, x []float64, probability, eta float64, rng *rand.Rand) {
+	for i := range x {
+		if rng.Float64() >= probability {
+			continue
+		}
+		var lower, upper = p.lower[i], p.upper[i]
+		var width = upper - lower
+		if width == 0 {
+			continue
+		}
+		var r = rng.Float64()
+		var deltaq float64
+		if r < 0.5 {
+			var xy = 1 - (x[i]-lower)/width
+			deltaq = math.Pow(2*r+(1-2*r)*math.Pow(xy, eta+1), 1/(eta+1)) - 1
+		} else {
+			var xy = 1 - (upper-x[i])/width
+			deltaq = 1 - math.Pow(2*(1-r)+2*(r-0.5)*math.Pow(xy, eta+1), 1/(eta+1))
+		}
+		x[i] = math.Min(math.Max(x[i]+deltaq*width, lower), upper)
+	}
+}